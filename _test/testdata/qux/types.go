@@ -0,0 +1,125 @@
+package qux
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/totvs-cloud/pflagstruct/_test/testdata/qux/units"
+)
+
+type Server struct {
+	Host    string   `json:"Host"`
+	Port    uint32   `json:"Port"`
+	MaxSize *uint64  `json:"MaxSize,omitempty"`
+	Weights []uint16 `json:"Weights,omitempty"`
+}
+
+type Timeouts struct {
+	Read    time.Duration   `json:"Read"`
+	Write   *time.Duration  `json:"Write,omitempty"`
+	Retries []time.Duration `json:"Retries,omitempty"`
+}
+
+type Event struct {
+	Created time.Time  `json:"Created"`
+	Day     *time.Time `json:"Day,omitempty" layout:"DateOnly"`
+}
+
+type Region string
+
+type Replicas int32
+
+type Tier string
+
+const (
+	TierBasic   Tier = "basic"
+	TierPremium Tier = "premium"
+	// TierDefault shares its value with TierBasic
+	TierDefault = TierBasic
+)
+
+type Deployment struct {
+	Region   Region       `json:"Region"`
+	Replicas *Replicas    `json:"Replicas,omitempty"`
+	Size     units.Bytes  `json:"Size"`
+	Zones    []units.Zone `json:"Zones,omitempty"`
+	Tier     Tier         `json:"Tier"`
+}
+
+// CIDR implements pflag.Value.
+type CIDR struct {
+	IP   net.IP
+	Mask net.IPMask
+}
+
+func (c *CIDR) String() string {
+	return (&net.IPNet{IP: c.IP, Mask: c.Mask}).String()
+}
+
+func (c *CIDR) Set(s string) error {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return err
+	}
+
+	c.IP, c.Mask = network.IP, network.Mask
+
+	return nil
+}
+
+func (c *CIDR) Type() string {
+	return "cidr"
+}
+
+// Labels implements pflag.Value, accumulating every value it is set to.
+type Labels []string
+
+func (l *Labels) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *Labels) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func (l *Labels) Type() string {
+	return "labels"
+}
+
+// Version implements encoding.TextUnmarshaler and encoding.TextMarshaler.
+type Version struct {
+	Major int
+	Minor int
+}
+
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("v%d.%d", v.Major, v.Minor)), nil
+}
+
+func (v *Version) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "v%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+type Network struct {
+	Subnet  CIDR     `json:"Subnet"`
+	Labels  Labels   `json:"Labels,omitempty"`
+	Version *Version `json:"Version,omitempty"`
+	Gateway net.IP   `json:"Gateway,omitempty"`
+}
+
+type CommonOptions struct {
+	Verbose bool          `json:"Verbose"`
+	Timeout time.Duration `json:"Timeout"`
+}
+
+type CreateRequest struct {
+	CommonOptions
+	*Server `embed:"prefix"`
+	io.Reader
+	Name string `json:"Name"`
+}
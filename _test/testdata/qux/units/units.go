@@ -0,0 +1,5 @@
+package units
+
+type Bytes uint64
+
+type Zone string
@@ -0,0 +1,171 @@
+package code
+
+import (
+	"github.com/dave/jennifer/jen"
+	"github.com/pkg/errors"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+const (
+	PflagBackendName = "pflag" // Generates code for github.com/spf13/pflag, as used by cobra
+	FlagBackendName  = "flag"  // Generates code for the flag package of the standard library
+	CliBackendName   = "cli"   // Generates code for github.com/urfave/cli/v2
+)
+
+// Backend is the flags package the generated code registers and reads the flags with. The flags builder always calls
+// the methods of pflag.FlagSet on its flags, such as Int32P or GetStringSlice, so backends other than pflag generate an
+// adapter providing them over their own flag sets.
+type Backend interface {
+	// Name returns the name the backend is selected with by the --backend option.
+	Name() string
+	// FlagSet returns the type of the flag set received by the generated functions, named after Param.
+	FlagSet() *jen.Statement
+	// Param returns the name of the parameter holding the flag set received by the generated functions.
+	Param() string
+	// Source returns what the generated functions read the flags from, such as flags in GetUserFromFlags.
+	Source() string
+	// Registers reports whether the flags are registered to the flag set received by the generated functions, rather
+	// than declared by them, and fall back to environment variables after being parsed.
+	Registers() bool
+	// Flags returns the type of the flags held by the flags builder of the given struct.
+	Flags(st *projscan.Struct) *jen.Statement
+	// Wrap returns the expression turning the given flag set into the flags held by the flags builder.
+	Wrap(st *projscan.Struct, flags jen.Code) *jen.Statement
+	// Marks returns the statements that follow the registration of a flag, marking it as required, hidden or deprecated.
+	Marks(call *SetterCall) []jen.Code
+	// Unsupported returns the struct tags and `pflag` options of the given field that the backend cannot honour.
+	Unsupported(field *projscan.Field) []string
+	// Blocks returns the declarations the flags held by the flags builder depend on.
+	Blocks(st *projscan.Struct, flags *orderedmap.OrderedMap[string, []*projscan.Field]) []Block
+}
+
+// BackendOf returns the backend selected by the given name, defaulting to pflag.
+func BackendOf(name string) (Backend, error) {
+	switch name {
+	case "", PflagBackendName:
+		return &PflagBackend{}, nil
+	case FlagBackendName:
+		return &FlagBackend{}, nil
+	case CliBackendName:
+		return &CliBackend{}, nil
+	}
+
+	return nil, errors.Errorf("unknown backend %q, expected %q, %q or %q", name, PflagBackendName, FlagBackendName, CliBackendName)
+}
+
+// flagsBuilder returns the flags builder holding the flag set received by the generated functions.
+func flagsBuilder(backend Backend, name string, st *projscan.Struct) *jen.Statement {
+	return jen.Op("&").Id(name).Values(jen.Id("flags").Op(":").Add(backend.Wrap(st, jen.Id(backend.Param()))))
+}
+
+// PflagBackend generates code for github.com/spf13/pflag, whose flag sets are held by the flags builder as they are.
+type PflagBackend struct{}
+
+func (p *PflagBackend) Name() string {
+	return PflagBackendName
+}
+
+func (p *PflagBackend) FlagSet() *jen.Statement {
+	return jen.Op("*").Qual("github.com/spf13/pflag", "FlagSet")
+}
+
+func (p *PflagBackend) Param() string {
+	return "flags"
+}
+
+func (p *PflagBackend) Source() string {
+	return "flags"
+}
+
+func (p *PflagBackend) Registers() bool {
+	return true
+}
+
+func (p *PflagBackend) Flags(*projscan.Struct) *jen.Statement {
+	return p.FlagSet()
+}
+
+func (p *PflagBackend) Wrap(_ *projscan.Struct, flags jen.Code) *jen.Statement {
+	return jen.Add(flags)
+}
+
+func (p *PflagBackend) Marks(call *SetterCall) []jen.Code {
+	return call.Marks()
+}
+
+func (p *PflagBackend) Unsupported(*projscan.Field) []string {
+	return []string{}
+}
+
+func (p *PflagBackend) Blocks(*projscan.Struct, *orderedmap.OrderedMap[string, []*projscan.Field]) []Block {
+	return []Block{}
+}
+
+// FlagBackend generates code for the flag package of the standard library. The flag sets are wrapped by a generated
+// adapter registering every flag with flag.Var, since the standard library has no flags for slices nor for sized
+// integers. Shorthands are registered as flags of their own sharing the value, and flags cannot be hidden nor
+// deprecated, so fields asking for it are reported. Required flags are still checked by the generated getters.
+type FlagBackend struct{}
+
+func (f *FlagBackend) Name() string {
+	return FlagBackendName
+}
+
+func (f *FlagBackend) FlagSet() *jen.Statement {
+	return jen.Op("*").Qual("flag", "FlagSet")
+}
+
+func (f *FlagBackend) Param() string {
+	return "flags"
+}
+
+func (f *FlagBackend) Source() string {
+	return "flags"
+}
+
+func (f *FlagBackend) Registers() bool {
+	return true
+}
+
+func (f *FlagBackend) Flags(st *projscan.Struct) *jen.Statement {
+	return jen.Id(FlagSetAdapterName(st))
+}
+
+func (f *FlagBackend) Wrap(st *projscan.Struct, flags jen.Code) *jen.Statement {
+	return jen.Id(FlagSetAdapterName(st)).Values(jen.Dict{jen.Id("FlagSet"): flags})
+}
+
+func (f *FlagBackend) Marks(*SetterCall) []jen.Code {
+	return []jen.Code{}
+}
+
+func (f *FlagBackend) Unsupported(field *projscan.Field) []string {
+	unsupported := deprecationTags(field)
+	if FlagTagOf(field).Hidden {
+		unsupported = append([]string{FlagTagHidden}, unsupported...)
+	}
+
+	return unsupported
+}
+
+func (f *FlagBackend) Blocks(st *projscan.Struct, flags *orderedmap.OrderedMap[string, []*projscan.Field]) []Block {
+	return []Block{
+		&FlagSetAdapterStruct{Struct: st, Types: adapterFlagTypes(flags)},
+		&FlagSetValueStructs{Struct: st},
+	}
+}
+
+// deprecationTags returns the deprecation struct tags of the given field, for the backends whose flags cannot be
+// deprecated.
+func deprecationTags(field *projscan.Field) []string {
+	tags := make([]string, 0)
+	for _, tag := range []string{DeprecatedTag, ShorthandDeprecatedTag} {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
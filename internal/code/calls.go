@@ -3,6 +3,7 @@ package code
 import (
 	"fmt"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/dave/jennifer/jen"
@@ -12,52 +13,149 @@ import (
 )
 
 type SetterCall struct {
-	Prefix string
-	Struct *projscan.Struct
-	Field  *projscan.Field
+	Prefix    string
+	EnvPrefix string
+	Struct    *projscan.Struct
+	Field     *projscan.Field
+	Optional  bool // Indicates whether the field is enclosed in a struct behind a pointer, which may be left unset
 }
 
 func (s *SetterCall) Flag() string {
-	return changecase.Param(path.Join(s.Prefix, s.Field.Name))
+	return flagName(s.Prefix, s.Field)
+}
+
+// Shorthand returns the one-letter abbreviation of the flag set in the `pflag` struct tag of the field, if any.
+func (s *SetterCall) Shorthand() string {
+	return FlagTagOf(s.Field).Shorthand
 }
 
 func (s *SetterCall) CobraMethod() string {
 	switch KindOf(s.Field) {
-	case FieldKindTCloudTag, FieldKindStringMap:
+	case FieldKindKeyValue, FieldKindStringMap:
 		return "StringSlice"
+	case FieldKindStructSlice:
+		return "StringArray"
 	}
 
-	var suffix string
-	if s.Field.Array {
-		suffix = "Slice"
+	return changecase.Pascal(flagType(s.Field))
+}
+
+func (s *SetterCall) UsageMessage() string {
+	doc := s.Usage()
+	if name := envVarOf(s.EnvPrefix, s.Flag(), s.Field); name != "" {
+		doc = strings.TrimSpace(doc + " (env: " + name + ")")
 	}
 
-	return changecase.Pascal(path.Join(s.Field.Type.String(), suffix))
+	return doc
 }
 
-func (s *SetterCall) UsageMessage() string {
+// Usage returns the usage message of the flag without its environment variable, for flags packages that show it by
+// themselves.
+func (s *SetterCall) Usage() string {
 	doc := strings.TrimSpace(s.Field.Doc)
 
 	switch KindOf(s.Field) {
-	case FieldKindStringMap, FieldKindTCloudTag:
-		msg := fmt.Sprintf("the desired key-value pairs separated by commas (%s key1=value1,key2=value2,key3=value3)", s.Flag())
-		if len(doc) > 0 {
-			doc += ". Provide " + msg
+	case FieldKindStringMap, FieldKindKeyValue:
+		doc = withUsageHint(doc, mapUsageHint(s.Field, s.Flag()))
+	case FieldKindStructSlice:
+		doc = withUsageHint(doc, fmt.Sprintf("one item per flag, either as key-value pairs separated by commas or as a JSON object (%s key1=value1,key2=value2 or %s '{\"key1\":\"value1\"}')", s.Flag(), s.Flag()))
+	case FieldKindDuration:
+		if s.Field.Array {
+			doc = withUsageHint(doc, fmt.Sprintf("durations separated by commas (%s 30s,5m,1h30m)", s.Flag()))
 		} else {
-			doc += "provide " + msg
+			doc = withUsageHint(doc, "a duration such as 30s, 5m or 1h30m")
+		}
+	case FieldKindTime:
+		if s.Field.Array {
+			doc = withUsageHint(doc, "values separated by commas, each one being "+TimeLayoutOf(s.Field).Description)
+		} else {
+			doc = withUsageHint(doc, TimeLayoutOf(s.Field).Description)
+		}
+	case FieldKindNative:
+		if s.Field.TypeRef != nil && s.Field.TypeRef.IsEnum() {
+			allowed := strings.Join(s.Field.TypeRef.ValueList(), ", ")
+			if s.Field.Array {
+				doc = withUsageHint(doc, "values separated by commas, each one being one of: "+allowed)
+			} else {
+				doc = withUsageHint(doc, "one of: "+allowed)
+			}
 		}
 	}
 
 	return doc
 }
 
+// mapUsageHint returns the hint describing the key-value pairs accepted by the flag of a map or of a slice of key-value
+// structs, naming the type of the values when they are not strings and the constants allowed as keys or values.
+func mapUsageHint(field *projscan.Field, flag string) string {
+	valueType, ok := StringMapValueOf(field)
+	if valueType == StringSliceMapValue {
+		return fmt.Sprintf("the desired key-value pairs separated by commas, repeating a key to give it several values (%s key1=value1,key1=value2,key2=value3)", flag)
+	}
+
+	if !ok || (valueType == projscan.FieldTypeString && field.MapValue.TypeRef == nil) {
+		hint := fmt.Sprintf("the desired key-value pairs separated by commas (%s key1=value1,key2=value2,key3=value3)", flag)
+		if ok {
+			hint += allowedMapConstants("keys", field.MapKey)
+		}
+
+		return hint
+	}
+
+	name := valueType.String()
+	switch {
+	case field.MapValue.TypeRef != nil:
+		name = strings.ToLower(field.MapValue.TypeRef.Name)
+	case valueType == projscan.DurationFieldType:
+		name = "duration"
+	}
+
+	hint := fmt.Sprintf("the desired key-value pairs separated by commas, with values of type %s (%s key1=%s,key2=%s)", name, flag, name, name)
+
+	return hint + allowedMapConstants("keys", field.MapKey) + allowedMapConstants("values", field.MapValue)
+}
+
+// allowedMapConstants returns the part of the usage hint of a map listing the constants allowed as its keys or values,
+// or an empty string if their type is not an enumeration.
+func allowedMapConstants(what string, field *projscan.Field) string {
+	if field.TypeRef == nil || !field.TypeRef.IsEnum() {
+		return ""
+	}
+
+	return fmt.Sprintf(", the %s being one of: %s", what, strings.Join(field.TypeRef.ValueList(), ", "))
+}
+
+// withUsageHint appends to the field documentation a hint describing the value accepted by the flag.
+func withUsageHint(doc, msg string) string {
+	doc = strings.TrimSuffix(doc, ".")
+	if len(doc) > 0 {
+		return doc + ". Provide " + msg
+	}
+
+	return "provide " + msg
+}
+
 func (s *SetterCall) DefaultValue() *jen.Statement {
+	if value, ok := s.Field.Tag.Lookup(DefaultTag); ok {
+		// invalid default values are reported before generating any code
+		if statement, err := DefaultValueOf(s.Field, value); err == nil {
+			return statement
+		}
+	}
+
 	if s.Field.Array {
 		return jen.Nil()
 	}
 
+	if KindOf(s.Field) == FieldKindTime {
+		return jen.Lit("")
+	}
+
 	switch s.Field.Type {
-	case projscan.FieldTypeInt, projscan.FieldTypeInt8, projscan.FieldTypeInt16, projscan.FieldTypeInt32, projscan.FieldTypeInt64:
+	case projscan.DurationFieldType:
+		return jen.Lit(0)
+	case projscan.FieldTypeInt, projscan.FieldTypeInt8, projscan.FieldTypeInt16, projscan.FieldTypeInt32, projscan.FieldTypeInt64,
+		projscan.FieldTypeUint, projscan.FieldTypeUint8, projscan.FieldTypeUint16, projscan.FieldTypeUint32, projscan.FieldTypeUint64:
 		return jen.Lit(0)
 	case projscan.FieldTypeFloat32, projscan.FieldTypeFloat64:
 		return jen.Lit(0.0)
@@ -71,34 +169,115 @@ func (s *SetterCall) DefaultValue() *jen.Statement {
 }
 
 func (s *SetterCall) Statement() *jen.Statement {
+	if KindOf(s.Field) == FieldKindValue {
+		return s.Registration(s.FlagValue())
+	}
+
+	return s.Registration(s.DefaultValue())
+}
+
+// Registration returns the call that registers the flag with the given default value or, for fields whose type parses
+// flag values by itself, with the given pflag.Value.
+func (s *SetterCall) Registration(value jen.Code) *jen.Statement {
 	switch KindOf(s.Field) {
-	case FieldKindTCloudTag, FieldKindStringMap, FieldKindNative:
+	case FieldKindKeyValue, FieldKindStringMap, FieldKindStructSlice, FieldKindNative, FieldKindDuration, FieldKindTime:
+		if shorthand := s.Shorthand(); shorthand != "" {
+			return jen.Id("cf").
+				Dot("flags").Dot(s.CobraMethod()+"P").
+				Call(jen.Lit(s.Flag()), jen.Lit(shorthand), value, jen.Lit(s.UsageMessage()))
+		}
+
 		return jen.Id("cf").
 			Dot("flags").Dot(s.CobraMethod()).
-			Call(jen.Lit(s.Flag()), s.DefaultValue(), jen.Lit(s.UsageMessage()))
+			Call(jen.Lit(s.Flag()), value, jen.Lit(s.UsageMessage()))
+	case FieldKindValue:
+		if shorthand := s.Shorthand(); shorthand != "" {
+			return jen.Id("cf").
+				Dot("flags").Dot("VarP").
+				Call(value, jen.Lit(s.Flag()), jen.Lit(shorthand), jen.Lit(s.UsageMessage()))
+		}
+
+		return jen.Id("cf").
+			Dot("flags").Dot("Var").
+			Call(value, jen.Lit(s.Flag()), jen.Lit(s.UsageMessage()))
 	}
 
 	return nil
 }
 
-type GetterCall struct {
-	Prefix  string
-	Struct  *projscan.Struct
-	Pointer bool
-	Field   *projscan.Field
+// RequiredAnnotation is the flag annotation cobra checks before running a command, set by cobra.MarkFlagRequired.
+const RequiredAnnotation = "cobra_annotation_bash_completion_one_required_flag"
+
+// Marks returns the statements that follow the registration of the flag, marking it as required to cobra, hidden or
+// deprecated. The generated getters check required flags by themselves, so the mark is set through pflag without
+// depending on cobra. Optional fields are not marked, since their flags are only required when their struct is set,
+// nor are the flags read from environment variables, since cobra would reject the command before they are read.
+func (s *SetterCall) Marks() []jen.Code {
+	marks := make([]jen.Code, 0)
+
+	tag := FlagTagOf(s.Field)
+	if tag.Required && !s.Optional && envVarOf(s.EnvPrefix, s.Flag(), s.Field) == "" {
+		marks = append(marks, jen.Id("_").Op("=").Id("cf").Dot("flags").Dot("SetAnnotation").
+			Call(jen.Lit(s.Flag()), jen.Lit(RequiredAnnotation), jen.Index().String().Values(jen.Lit("true"))))
+	}
+
+	if tag.Hidden {
+		marks = append(marks, jen.Id("_").Op("=").Id("cf").Dot("flags").Dot("MarkHidden").Call(jen.Lit(s.Flag())))
+	}
+
+	if msg, ok := s.Field.Tag.Lookup(DeprecatedTag); ok {
+		marks = append(marks, jen.Id("_").Op("=").Id("cf").Dot("flags").Dot("MarkDeprecated").Call(jen.Lit(s.Flag()), jen.Lit(msg)))
+	}
+
+	if msg, ok := s.Field.Tag.Lookup(ShorthandDeprecatedTag); ok {
+		marks = append(marks, jen.Id("_").Op("=").Id("cf").Dot("flags").Dot("MarkShorthandDeprecated").Call(jen.Lit(s.Flag()), jen.Lit(msg)))
+	}
+
+	return marks
 }
 
-func (g *GetterCall) CobraMethod() string {
-	var suffix string
-	if g.Field.Array {
-		suffix = "Slice"
+// FlagValue returns the pflag.Value registered for fields whose type parses flag values by itself, holding the zero
+// value of the type. The zero value is no default set by the user, so text values leave it out of the usage message.
+func (s *SetterCall) FlagValue() *jen.Statement {
+	if s.Field.TypeRef.PflagValue {
+		return jen.New(convertedType(s.Field))
 	}
 
-	return changecase.Pascal(path.Join("get", g.Field.Type.String(), suffix))
+	return jen.Op("&").Id(TextValueName(s.Struct)).Values(jen.Dict{
+		jen.Id("value"): jen.New(convertedType(s.Field)),
+		jen.Id("typ"):   jen.Lit(strings.ToLower(s.Field.TypeRef.Name)),
+		jen.Id("unset"): jen.True(),
+	})
+}
+
+// FlagValueOf returns the pflag.Value registered for fields whose type parses flag values by itself, given a pointer to
+// the value of the type. Types implementing encoding.TextUnmarshaler and encoding.TextMarshaler are wrapped by the
+// generated text value adapter.
+func (s *SetterCall) FlagValueOf(value jen.Code) *jen.Statement {
+	if s.Field.TypeRef.PflagValue {
+		return jen.Add(value)
+	}
+
+	return jen.Op("&").Id(TextValueName(s.Struct)).Values(jen.Dict{
+		jen.Id("value"): value,
+		jen.Id("typ"):   jen.Lit(strings.ToLower(s.Field.TypeRef.Name)),
+	})
+}
+
+type GetterCall struct {
+	Prefix     string
+	FlagPrefix string
+	Struct     *projscan.Struct
+	Pointer    bool
+	Field      *projscan.Field
+}
+
+func (g *GetterCall) CobraMethod() string {
+	return changecase.Pascal(path.Join("get", flagType(g.Field)))
 }
 
 func (g *GetterCall) Flag() string {
-	return changecase.Param(path.Join(g.Prefix, g.Field.Name))
+	return flagName(g.FlagPrefix, g.Field)
 }
 
 func (g *GetterCall) Statement() *jen.Statement {
@@ -110,13 +289,44 @@ func (g *GetterCall) Statement() *jen.Statement {
 	}
 
 	switch KindOf(g.Field) {
-	case FieldKindNative:
+	case FieldKindNative, FieldKindDuration:
+		if needsConversion(g.Field) {
+			return jen.If(jen.List(jen.Id("flagValue"), jen.Err()).Op(":=").
+				Id("cf").Dot("flags").Dot(g.CobraMethod()).Call(jen.Lit(g.Flag())), jen.Err().Op("!=").Nil()).
+				Block(
+					jen.Return().List(returnId, jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+g.Flag()+"\" from command flags: %w"), jen.Err())),
+				).Else().If(isFlagSet(g.Field, g.Flag(), "flagValue")).
+				Block(
+					append(
+						enumValidation(g.Field, g.Flag(), "flagValue", returnId),
+						convertedAssignment(jen.Id(changecase.Camel(g.Struct.Name)).Dot(g.Field.Name), g.Field, g.Flag(), "flagValue", returnId)...,
+					)...,
+				)
+		}
+
+		if g.Field.Pointer {
+			return jen.If(jen.List(jen.Id("flagValue"), jen.Err()).Op(":=").
+				Id("cf").Dot("flags").Dot(g.CobraMethod()).Call(jen.Lit(g.Flag())), jen.Err().Op("!=").Nil()).
+				Block(
+					jen.Return().List(returnId, jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+g.Flag()+"\" from command flags: %w"), jen.Err())),
+				).Else().If(isFlagSet(g.Field, g.Flag(), "flagValue")).
+				Block(
+					id.Dot(g.Field.Name).Op("=").Op("&").Id("flagValue"),
+				)
+		}
+
 		return jen.If(jen.List(id.Dot(g.Field.Name), jen.Err()).Op("=").
 			Id("cf").Dot("flags").Dot(g.CobraMethod()).Call(jen.Lit(g.Flag())), jen.Err().Op("!=").Nil()).
 			Block(
 				jen.Return().List(returnId, jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+g.Flag()+"\" from command flags: %w"), jen.Err())),
 			)
-	case FieldKindStruct, FieldKindTCloudTag, FieldKindStringMap:
+	case FieldKindValue:
+		return jen.If(jen.List(jen.Id("flagValue"), jen.Err()).Op(":=").
+			Id("cf").Dot(changecase.Camel(path.Join("Get", g.Prefix, g.Field.Name))).Call(), jen.Err().Op("!=").Nil()).
+			Block(jen.Return().List(returnId, jen.Err())).
+			Else().If(jen.Id("flagValue").Op("!=").Nil()).
+			Block(id.Dot(g.Field.Name).Op("=").Add(flagValueAssignment(g.Field, "flagValue")))
+	case FieldKindStruct, FieldKindKeyValue, FieldKindStringMap, FieldKindStructSlice, FieldKindTime:
 		return jen.If(jen.List(id.Dot(g.Field.Name), jen.Err()).Op("=").
 			Id("cf").Dot(changecase.Camel(path.Join("Get", g.Prefix, g.Field.Name))).Call(), jen.Err().Op("!=").Nil()).
 			Block(jen.Return().List(returnId, jen.Err()))
@@ -126,23 +336,20 @@ func (g *GetterCall) Statement() *jen.Statement {
 }
 
 type PointerGetterCall struct {
-	Prefix  string
-	Struct  *projscan.Struct
-	Pointer bool
-	Field   *projscan.Field
+	Prefix     string
+	FlagPrefix string
+	Struct     *projscan.Struct
+	Pointer    bool
+	Field      *projscan.Field
+	Nested     []string // Flags declared for the fields of the field, when it is a struct
 }
 
 func (g *PointerGetterCall) CobraMethod() string {
-	var suffix string
-	if g.Field.Array {
-		suffix = "Slice"
-	}
-
-	return changecase.Pascal(path.Join("get", g.Field.Type.String(), suffix))
+	return changecase.Pascal(path.Join("get", flagType(g.Field)))
 }
 
 func (g *PointerGetterCall) Flag() string {
-	return changecase.Param(path.Join(g.Prefix, g.Field.Name))
+	return flagName(g.FlagPrefix, g.Field)
 }
 
 func (g *PointerGetterCall) Statement() *jen.Statement {
@@ -156,7 +363,24 @@ func (g *PointerGetterCall) Statement() *jen.Statement {
 	}
 
 	switch KindOf(g.Field) {
-	case FieldKindNative:
+	case FieldKindNative, FieldKindDuration:
+		if needsConversion(g.Field) {
+			assignments := append(
+				enumValidation(g.Field, g.Flag(), flagValue, returnId),
+				jen.If(jen.Id(structName).Op("==").Nil()).Block(
+					jen.Id(structName).Op("=").New(jen.Qual(g.Struct.Package.Path, g.Struct.Name)),
+				),
+			)
+			assignments = append(assignments, convertedAssignment(jen.Id(structName).Dot(fieldName), g.Field, g.Flag(), flagValue, returnId)...)
+
+			return jen.If(jen.List(jen.Id(flagValue), jen.Err()).Op(":=").
+				Id("cf").Dot("flags").Dot(g.CobraMethod()).Call(jen.Lit(g.Flag())), jen.Err().Op("!=").Nil()).
+				Block(
+					jen.Return().List(returnId, jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+g.Flag()+"\" from command flags: %w"), jen.Err())),
+				).Else().If(isFlagSet(g.Field, g.Flag(), flagValue)).
+				Block(assignments...)
+		}
+
 		var assigment1, assigment2 *jen.Statement
 		if !g.Field.Pointer {
 			assigment1 = jen.Id(fieldName).Op(":").Id(flagValue)
@@ -170,25 +394,25 @@ func (g *PointerGetterCall) Statement() *jen.Statement {
 			Id("cf").Dot("flags").Dot(g.CobraMethod()).Call(jen.Lit(g.Flag())), jen.Err().Op("!=").Nil()).
 			Block(
 				jen.Return().List(returnId, jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+g.Flag()+"\" from command flags: %w"), jen.Err())),
-			).Else().If(g.CompareToDefaultValue(jen.Id(flagValue).Op("!=")).Op("&&").Id(structName).Op("==").Nil()).
+			).Else().If(jen.Parens(isFlagSet(g.Field, g.Flag(), flagValue)).Op("&&").Id(structName).Op("==").Nil()).
 			Block(
 				jen.Id(structName).Op("=").Op("&").Qual(g.Struct.Package.Path, g.Struct.Name).Values(assigment1),
-			).Else().If(g.CompareToDefaultValue(jen.Id(flagValue).Op("!="))).
+			).Else().If(isFlagSet(g.Field, g.Flag(), flagValue)).
 			Block(
 				assigment2,
 			)
-	case FieldKindStruct, FieldKindTCloudTag, FieldKindStringMap:
-		if g.Field.Pointer {
+	case FieldKindStruct, FieldKindKeyValue, FieldKindStringMap, FieldKindStructSlice, FieldKindTime, FieldKindValue:
+		if g.IsSetCondition(flagValue) != nil {
 			return jen.If(jen.List(jen.Id(flagValue), jen.Err()).Op(":=").
 				Id("cf").Dot(changecase.Camel(path.Join("Get", g.Prefix, fieldName))).Call(), jen.Err().Op("!=").Nil()).
 				Block(
 					jen.Return().List(returnId, jen.Err()),
-				).Else().If(g.CompareToDefaultValue(jen.Id(flagValue).Op("!=")).Op("&&").Id(structName).Op("==").Nil()).
+				).Else().If(g.IsSetCondition(flagValue).Op("&&").Id(structName).Op("==").Nil()).
 				Block(
-					jen.Id(structName).Op("=").Op("&").Qual(g.Struct.Package.Path, g.Struct.Name).Values(jen.Id(fieldName).Op(":").Id(flagValue)),
-				).Else().If(g.CompareToDefaultValue(jen.Id(flagValue).Op("!="))).
+					jen.Id(structName).Op("=").Op("&").Qual(g.Struct.Package.Path, g.Struct.Name).Values(jen.Id(fieldName).Op(":").Add(flagValueAssignment(g.Field, flagValue))),
+				).Else().If(g.IsSetCondition(flagValue)).
 				Block(
-					jen.Id(structName).Dot(fieldName).Op("=").Id(flagValue),
+					jen.Id(structName).Dot(fieldName).Op("=").Add(flagValueAssignment(g.Field, flagValue)),
 				)
 		} else {
 			return jen.If(jen.List(jen.Id(flagValue), jen.Err()).Op(":=").
@@ -208,21 +432,245 @@ func (g *PointerGetterCall) Statement() *jen.Statement {
 	return nil
 }
 
+// IsSetCondition returns the condition under which the value retrieved by a getter method is assigned to the struct,
+// or nil if the value must always be assigned. As with the flags of basic types, maps and structs are assigned once
+// one of their flags is changed or they hold a default, so that the struct is left nil when none of its flags is set.
+func (g *PointerGetterCall) IsSetCondition(flagValue string) *jen.Statement {
+	switch {
+	case KindOf(g.Field) == FieldKindValue:
+		return jen.Id(flagValue).Op("!=").Nil()
+	case KindOf(g.Field) == FieldKindStringMap, KindOf(g.Field) == FieldKindKeyValue:
+		pairs := jen.Id(flagValue)
+		if g.Field.ArrayPointer {
+			pairs = jen.Op("*").Id(flagValue)
+		}
+
+		changed := jen.Id("cf").Dot("flags").Dot("Changed").Call(jen.Lit(g.Flag()))
+		return jen.Parens(changed.Op("||").Len(pairs).Op(">").Lit(0))
+	case g.Field.Pointer:
+		return g.CompareToDefaultValue(jen.Id(flagValue).Op("!="))
+	case KindOf(g.Field) == FieldKindTime && g.Field.Array, KindOf(g.Field) == FieldKindStructSlice:
+		return jen.Id(flagValue).Op("!=").Nil()
+	case KindOf(g.Field) == FieldKindTime:
+		return jen.Op("!").Id(flagValue).Dot("IsZero").Call()
+	case KindOf(g.Field) == FieldKindStruct:
+		condition := jen.Null()
+		for _, flag := range g.Nested {
+			condition.Id("cf").Dot("flags").Dot("Changed").Call(jen.Lit(flag)).Op("||")
+		}
+
+		return jen.Parens(condition.Op("!").Qual("reflect", "ValueOf").Call(jen.Id(flagValue)).Dot("IsZero").Call())
+	default:
+		return nil
+	}
+}
+
+// flagValueAssignment returns the expression assigned to the field from the value retrieved by a getter method. The
+// getter methods of fields whose type parses flag values by itself always return a pointer, dereferenced here when the
+// field is not a pointer.
+func flagValueAssignment(field *projscan.Field, flagValue string) *jen.Statement {
+	if KindOf(field) == FieldKindValue && !field.Pointer {
+		return jen.Op("*").Id(flagValue)
+	}
+
+	return jen.Id(flagValue)
+}
+
 func (g *PointerGetterCall) CompareToDefaultValue(statement *jen.Statement) *jen.Statement {
-	if g.Field.Array {
+	return compareToDefaultValue(g.Field, statement)
+}
+
+// compareToDefaultValue completes the given statement with the zero value of the flag type of the field.
+func compareToDefaultValue(field *projscan.Field, statement *jen.Statement) *jen.Statement {
+	if field.Array {
 		return statement.Nil()
 	}
 
-	switch g.Field.Type {
+	switch field.Type {
+	case projscan.DurationFieldType:
+		return statement.Lit(0)
 	case projscan.FieldTypeString:
 		return statement.Lit("")
 	case projscan.FieldTypeBool:
 		return statement.False()
 	case projscan.FieldTypeFloat32, projscan.FieldTypeFloat64:
 		return statement.Lit(0.0)
-	case projscan.FieldTypeInt, projscan.FieldTypeInt8, projscan.FieldTypeInt16, projscan.FieldTypeInt32, projscan.FieldTypeInt64:
+	case projscan.FieldTypeInt, projscan.FieldTypeInt8, projscan.FieldTypeInt16, projscan.FieldTypeInt32, projscan.FieldTypeInt64,
+		projscan.FieldTypeUint, projscan.FieldTypeUint8, projscan.FieldTypeUint16, projscan.FieldTypeUint32, projscan.FieldTypeUint64:
 		return statement.Lit(0)
 	default:
 		return statement.Nil()
 	}
 }
+
+// flagType returns the name of the pflag type used to register and retrieve the given field, such as "int32" or
+// "string/slice", to be turned into method names like Int32 or GetStringSlice.
+func flagType(field *projscan.Field) string {
+	name := field.Type.String()
+	switch KindOf(field) {
+	case FieldKindDuration:
+		name = "duration"
+	case FieldKindTime:
+		name = "string"
+	}
+
+	if field.Array {
+		return path.Join(sliceElementType(projscan.FieldType(name)).String(), "slice")
+	}
+
+	return name
+}
+
+// sliceElementType returns the element type of the pflag slice used to register a slice of the given type. pflag only
+// provides UintSlice for unsigned integers, so sized unsigned slices are read as []uint and converted afterwards.
+func sliceElementType(fieldType projscan.FieldType) projscan.FieldType {
+	switch fieldType {
+	case projscan.FieldTypeUint8, projscan.FieldTypeUint16, projscan.FieldTypeUint32, projscan.FieldTypeUint64:
+		return projscan.FieldTypeUint
+	default:
+		return fieldType
+	}
+}
+
+// needsConversion returns true if the value retrieved from the flag must be converted before being assigned to the
+// field, either because the field has a named type or because pflag has no slice of the field type.
+func needsConversion(field *projscan.Field) bool {
+	return field.TypeRef != nil || (field.Array && sliceElementType(field.Type) != field.Type)
+}
+
+// convertedType returns the type the value retrieved from the flag is converted to before being assigned to the field.
+func convertedType(field *projscan.Field) *jen.Statement {
+	if field.TypeRef != nil {
+		return jen.Qual(field.TypeRef.Package.ImportPath(), field.TypeRef.Name)
+	}
+
+	return jen.Id(field.Type.String())
+}
+
+// isConvertedValueSet returns the condition under which the value retrieved from the flag is converted and assigned
+// to the field.
+func isConvertedValueSet(field *projscan.Field, flagValue string) *jen.Statement {
+	if field.Array {
+		return jen.Len(jen.Id(flagValue)).Op(">").Lit(0)
+	}
+
+	return compareToDefaultValue(field, jen.Id(flagValue).Op("!="))
+}
+
+// isFlagSet returns the condition under which the value retrieved from the flag is assigned to the field: the flag was
+// set on the command line, even if to the zero value, or it holds a default value other than the zero value. Pointer
+// fields are left nil otherwise.
+func isFlagSet(field *projscan.Field, flag string, flagValue string) *jen.Statement {
+	return jen.Id("cf").Dot("flags").Dot("Changed").Call(jen.Lit(flag)).Op("||").Add(isConvertedValueSet(field, flagValue))
+}
+
+// convertedAssignment returns the statements that assign the given flag value to the target field, converting it to
+// the field type. Slices are converted item by item, and items out of the range of the field type or outside the
+// enumeration of the field's named type make the getter return an error.
+func convertedAssignment(target *jen.Statement, field *projscan.Field, flag string, flagValue string, returnId *jen.Statement) []jen.Code {
+	switch {
+	case field.Array:
+		loop := make([]jen.Code, 0)
+		if condition := outOfRangeCondition(field, "item"); condition != nil {
+			loop = append(loop, jen.If(condition).Block(
+				returnError(returnId, jen.Qual("fmt", "Errorf").Call(jen.Lit(fmt.Sprintf("error retrieving %q from command flags: value %%d out of range for %s", flag, field.Type)), jen.Id("item"))),
+			))
+		}
+
+		if field.TypeRef != nil && field.TypeRef.IsEnum() {
+			loop = append(loop, jen.If(invalidEnumCondition(field, "item")).Block(
+				returnError(returnId, invalidEnumError(field, flag, "item")),
+			))
+		}
+
+		loop = append(loop, target.Clone().Op("=").Append(target.Clone(), convertedType(field).Call(jen.Id("item"))))
+
+		return []jen.Code{
+			jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Id(flagValue)).Block(loop...),
+		}
+	case field.Pointer:
+		return []jen.Code{
+			jen.Id("converted").Op(":=").Add(convertedType(field).Call(jen.Id(flagValue))),
+			target.Clone().Op("=").Op("&").Id("converted"),
+		}
+	default:
+		return []jen.Code{
+			target.Clone().Op("=").Add(convertedType(field).Call(jen.Id(flagValue))),
+		}
+	}
+}
+
+// outOfRangeCondition returns the condition that holds when the given item of a slice of sized unsigned integers, read
+// as a uint, does not fit in the field type, or nil if every uint fits in it.
+func outOfRangeCondition(field *projscan.Field, item string) *jen.Statement {
+	size := bitSize(field.Type)
+	if sliceElementType(field.Type) == field.Type || size == 0 || size == 64 {
+		return nil
+	}
+
+	return jen.Id(item).Op(">").Qual("math", "MaxUint"+strconv.Itoa(size))
+}
+
+// enumValidation returns the statements that make the getter return an error when the given scalar value is not one of
+// the constants declared with the field's named type, or no statements if the named type is not an enumeration.
+// Slices are validated item by item by convertedAssignment.
+func enumValidation(field *projscan.Field, flag string, value string, returnId *jen.Statement) []jen.Code {
+	if field.Array || field.TypeRef == nil || !field.TypeRef.IsEnum() {
+		return []jen.Code{}
+	}
+
+	return []jen.Code{
+		jen.If(invalidEnumCondition(field, value)).Block(
+			returnError(returnId, invalidEnumError(field, flag, value)),
+		),
+	}
+}
+
+// returnError returns the statement returning the given error, preceded by returnId unless it is nil, for functions
+// that only return an error.
+func returnError(returnId *jen.Statement, err jen.Code) *jen.Statement {
+	if returnId == nil {
+		return jen.Return(err)
+	}
+
+	return jen.Return().List(returnId, err)
+}
+
+// invalidEnumCondition returns the condition that holds when the given value is not one of the constants declared with
+// the field's named type.
+func invalidEnumCondition(field *projscan.Field, value string) *jen.Statement {
+	condition := jen.Empty()
+	for i, c := range field.TypeRef.Values {
+		if i > 0 {
+			condition.Op("&&")
+		}
+
+		condition.Id(value).Op("!=").Add(enumLiteral(field, c.Value))
+	}
+
+	return condition
+}
+
+// invalidEnumError returns the error reported when the given value is not one of the constants declared with the
+// field's named type.
+func invalidEnumError(field *projscan.Field, flag string, value string) *jen.Statement {
+	verb := "%v"
+	if field.Type == projscan.FieldTypeString {
+		verb = "%q"
+	}
+
+	allowed := strings.ReplaceAll(strings.Join(field.TypeRef.ValueList(), ", "), "%", "%%")
+	msg := fmt.Sprintf("error retrieving %q from command flags: invalid value %s, allowed values are %s", flag, verb, allowed)
+
+	return jen.Qual("fmt", "Errorf").Call(jen.Lit(msg), jen.Id(value))
+}
+
+// enumLiteral returns the literal of a constant value declared with the field's named type. Numeric constants are
+// rendered exactly as they were resolved, so they stay untyped in the generated comparison.
+func enumLiteral(field *projscan.Field, value string) *jen.Statement {
+	if field.Type == projscan.FieldTypeString {
+		return jen.Lit(value)
+	}
+
+	return jen.Id(value)
+}
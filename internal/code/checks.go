@@ -0,0 +1,313 @@
+package code
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"strings"
+
+	changecase "github.com/ku/go-change-case"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/totvs-cloud/pflagstruct/projscan"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"golang.org/x/exp/slog"
+)
+
+// reservedFlags are the flags registered by pflag and cobra themselves.
+var reservedFlags = []string{"help"}
+
+// FlagDeclaration is a flag along with the struct field it was generated from.
+type FlagDeclaration struct {
+	Flag      string            // Name of the flag
+	Shorthand string            // One-letter abbreviation of the flag, if any
+	Prefix    string            // Prefix of the flag, given by the structs enclosing the field
+	Path      string            // Go path of the field, such as Baz.Quux.ID
+	Parents   []*projscan.Field // Struct fields enclosing the field, from the outermost one
+	Field     *projscan.Field   // Field registered as the flag
+}
+
+// GoPath returns the path of the field relative to the struct declaring the flags, such as Quux.ID.
+func (d *FlagDeclaration) GoPath() string {
+	names := make([]string, 0, len(d.Parents)+1)
+	for _, parent := range d.Parents {
+		names = append(names, parent.Name)
+	}
+
+	return strings.Join(append(names, d.Field.Name), ".")
+}
+
+// OptionalPath returns the path of the innermost struct behind a pointer enclosing the field, relative to the struct
+// declaring the flags, or an empty string if no pointer encloses it. Such a struct is left nil unless one of its flags
+// is set, so its required flags are only required when it is.
+func (d *FlagDeclaration) OptionalPath() string {
+	names, optional := make([]string, 0, len(d.Parents)), ""
+	for _, parent := range d.Parents {
+		names = append(names, parent.Name)
+		if parent.Pointer {
+			optional = strings.Join(names, ".")
+		}
+	}
+
+	return optional
+}
+
+// NestedIn returns true if the field is enclosed in the struct field referenced by the given prefix, as the prefixes
+// of the getter methods built from structReferences, such as limits-window for Limits.Window.
+func (d *FlagDeclaration) NestedIn(prefix string) bool {
+	var enclosing string
+	for _, parent := range d.Parents {
+		if enclosing = changecase.Param(path.Join(enclosing, parent.Name)); enclosing == prefix {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JSONPath returns the path of the field relative to the struct declaring the flags as encoded by encoding/json, such
+// as quux.id, following the `json` struct tags and promoting embedded structs. It returns false if the field or one of
+// the structs enclosing it is left out of the JSON encoding.
+func (d *FlagDeclaration) JSONPath() (string, bool) {
+	names := make([]string, 0, len(d.Parents)+1)
+	for _, field := range append(append(make([]*projscan.Field, 0, len(d.Parents)+1), d.Parents...), d.Field) {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		switch {
+		case name == "-":
+			return "", false
+		case name != "":
+			names = append(names, name)
+		case !field.Embedded || KindOf(field) != FieldKindStruct:
+			names = append(names, field.Name)
+		}
+	}
+
+	return strings.Join(names, "."), true
+}
+
+// structDeclarations returns the flags generated for the given struct, following the same rules as structFlags.
+func (g *Generator) structDeclarations(st *projscan.Struct) ([]*FlagDeclaration, error) {
+	flds, err := g.fields.FindFieldsByStruct(st)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.fieldDeclarations(flds, st.Name, "", nil)
+}
+
+func (g *Generator) fieldDeclarations(flds []*projscan.Field, goPath, prefix string, parents []*projscan.Field) ([]*FlagDeclaration, error) {
+	declarations := make([]*FlagDeclaration, 0, len(flds))
+
+	for _, fld := range flds {
+		switch KindOf(fld) {
+		case FieldKindNative, FieldKindDuration, FieldKindTime, FieldKindValue, FieldKindKeyValue, FieldKindStringMap, FieldKindStructSlice:
+			declarations = append(declarations, &FlagDeclaration{
+				Flag:      flagName(prefix, fld),
+				Shorthand: FlagTagOf(fld).Shorthand,
+				Prefix:    prefix,
+				Path:      goPath + "." + fld.Name,
+				Parents:   parents,
+				Field:     fld,
+			})
+		case FieldKindStruct:
+			subFields, err := g.fields.FindFieldsByStruct(fld.StructRef)
+			if err != nil { // fieldFlags leaves these fields out as well
+				continue
+			}
+
+			enclosing := append(append(make([]*projscan.Field, 0, len(parents)+1), parents...), fld)
+
+			extracted, err := g.fieldDeclarations(subFields, goPath+"."+fld.Name, g.flagPrefixOf(prefix, fld), enclosing)
+			if err != nil {
+				return nil, err
+			}
+
+			declarations = append(declarations, extracted...)
+		default:
+			// the maps StringMapValueOf does not support, such as the maps of structs, are left out without a flag
+			if fld.MapKey != nil && !FlagTagOf(fld).Skip {
+				slog.Warn("map field type not supported", slog.String("Field", goPath+"."+fld.Name), slog.String("FieldType", fld.Type.String()))
+			}
+		}
+	}
+
+	return declarations, nil
+}
+
+// checkFlags returns an error listing, among the flags declared by the struct, every flag, shorthand, environment
+// variable or viper key declared more than once, every flag clashing with a reserved flag, every invalid default value,
+// every unknown tag option, every invalid deprecation, every shorthand longer than one character and every slice of
+// structs that cannot be read from key-value pairs as asked, along with the Go path and the position of each field
+// involved.
+func (g *Generator) checkFlags(st *projscan.Struct, declarations []*FlagDeclaration) error {
+	byFlag := orderedmap.New[string, []*FlagDeclaration]()
+	byShorthand := orderedmap.New[string, []*FlagDeclaration]()
+	byEnv := orderedmap.New[string, []*FlagDeclaration]()
+	byViperKey := orderedmap.New[string, []*FlagDeclaration]()
+
+	backend, err := BackendOf(g.options.Backend)
+	if err != nil {
+		return err
+	}
+
+	for _, declaration := range declarations {
+		declared, _ := byFlag.Get(declaration.Flag)
+		byFlag.Set(declaration.Flag, append(declared, declaration))
+
+		if declaration.Shorthand != "" {
+			declared, _ = byShorthand.Get(declaration.Shorthand)
+			byShorthand.Set(declaration.Shorthand, append(declared, declaration))
+		}
+
+		if name := envVarOf(g.options.EnvPrefix, declaration.Flag, declaration.Field); name != "" {
+			declared, _ = byEnv.Get(name)
+			byEnv.Set(name, append(declared, declaration))
+		}
+
+		if key, ok := declaration.ViperKey(); ok && g.options.Viper {
+			declared, _ = byViperKey.Get(key)
+			byViperKey.Set(key, append(declared, declaration))
+		}
+	}
+
+	messages := make([]string, 0)
+
+	for pair := byFlag.Oldest(); pair != nil; pair = pair.Next() {
+		flag, declared := pair.Key, pair.Value
+
+		switch {
+		case lo.Contains(reservedFlags, flag):
+			messages = append(messages, fmt.Sprintf("flag %q is reserved by pflag, but it is declared by:%s", flag, g.describeDeclarations(declared)))
+		case len(declared) > 1:
+			messages = append(messages, fmt.Sprintf("flag %q is declared more than once, by:%s", flag, g.describeDeclarations(declared)))
+		}
+	}
+
+	for _, declaration := range declarations {
+		if value, ok := declaration.Field.Tag.Lookup(DefaultTag); ok {
+			if _, err := DefaultValueOf(declaration.Field, value); err != nil {
+				messages = append(messages, fmt.Sprintf("default value %q of flag %q is invalid: %s, declared by:%s", value, declaration.Flag, err, g.describeDeclarations([]*FlagDeclaration{declaration})))
+			}
+
+			// the default of a required flag would never be read
+			if FlagTagOf(declaration.Field).Required {
+				messages = append(messages, fmt.Sprintf("flag %q is required, so it cannot have a default value, declared by:%s", declaration.Flag, g.describeDeclarations([]*FlagDeclaration{declaration})))
+			}
+		}
+	}
+
+	for _, declaration := range declarations {
+		if unknown := FlagTagOf(declaration.Field).Unknown; len(unknown) > 0 {
+			messages = append(messages, fmt.Sprintf("flag %q has unknown options %q in its %s struct tag, declared by:%s", declaration.Flag, unknown, FlagTagName, g.describeDeclarations([]*FlagDeclaration{declaration})))
+		}
+
+		// pflag refuses to deprecate flags without a message
+		for _, tag := range []string{DeprecatedTag, ShorthandDeprecatedTag} {
+			if msg, ok := declaration.Field.Tag.Lookup(tag); ok && strings.TrimSpace(msg) == "" {
+				messages = append(messages, fmt.Sprintf("flag %q has an empty %s struct tag, which must hold the message shown when it is used, declared by:%s", declaration.Flag, tag, g.describeDeclarations([]*FlagDeclaration{declaration})))
+			}
+		}
+
+		if _, ok := declaration.Field.Tag.Lookup(ShorthandDeprecatedTag); ok && declaration.Shorthand == "" {
+			messages = append(messages, fmt.Sprintf("flag %q has a %s struct tag but no shorthand, declared by:%s", declaration.Flag, ShorthandDeprecatedTag, g.describeDeclarations([]*FlagDeclaration{declaration})))
+		}
+
+		if problem := g.keyValueProblem(declaration.Field); problem != "" {
+			messages = append(messages, fmt.Sprintf("flag %q %s, declared by:%s", declaration.Flag, problem, g.describeDeclarations([]*FlagDeclaration{declaration})))
+		}
+
+		if unsupported := backend.Unsupported(declaration.Field); len(unsupported) > 0 {
+			messages = append(messages, fmt.Sprintf("flag %q uses %q, which the %s backend does not support, declared by:%s", declaration.Flag, unsupported, backend.Name(), g.describeDeclarations([]*FlagDeclaration{declaration})))
+		}
+	}
+
+	for pair := byShorthand.Oldest(); pair != nil; pair = pair.Next() {
+		shorthand, declared := pair.Key, pair.Value
+
+		switch {
+		case len(shorthand) > 1:
+			// pflag panics on shorthands longer than one ASCII character
+			messages = append(messages, fmt.Sprintf("shorthand %q must be a single character, but it is declared by:%s", shorthand, g.describeDeclarations(declared)))
+		case len(declared) > 1:
+			messages = append(messages, fmt.Sprintf("shorthand %q is declared more than once, by:%s", shorthand, g.describeDeclarations(declared)))
+		}
+	}
+
+	for pair := byEnv.Oldest(); pair != nil; pair = pair.Next() {
+		if name, declared := pair.Key, pair.Value; len(declared) > 1 {
+			messages = append(messages, fmt.Sprintf("environment variable %s is read by more than one flag, declared by:%s", name, g.describeDeclarations(declared)))
+		}
+	}
+
+	for pair := byViperKey.Oldest(); pair != nil; pair = pair.Next() {
+		if key, declared := pair.Key, pair.Value; len(declared) > 1 {
+			messages = append(messages, fmt.Sprintf("viper key %q is bound to more than one flag, declared by:%s", key, g.describeDeclarations(declared)))
+		}
+	}
+
+	if len(messages) > 0 {
+		return errors.Errorf("invalid flags found in %q:\n%s", st.Name, strings.Join(messages, "\n"))
+	}
+
+	return nil
+}
+
+// keyValueProblem describes why the given field cannot be read from key-value pairs as its `keyvalue` struct tag asks,
+// or why the fields it names cannot hold the keys and the values of the items. It returns an empty string if there is
+// no problem.
+func (g *Generator) keyValueProblem(field *projscan.Field) string {
+	if _, tagged := field.Tag.Lookup(KeyValueTag); tagged && KindOf(field) != FieldKindKeyValue {
+		return fmt.Sprintf("has a %s struct tag but is not a slice of structs", KeyValueTag)
+	}
+
+	keyValue, ok := KeyValueOf(field)
+	if !ok || KindOf(field) != FieldKindKeyValue {
+		return ""
+	}
+
+	if keyValue.Key == "" || keyValue.Value == "" || keyValue.Key == keyValue.Value {
+		return fmt.Sprintf("must name two different fields of its items in its %s struct tag, as in %s:\"Key,Value\"", KeyValueTag, KeyValueTag)
+	}
+
+	items, err := g.fields.FindFieldsByStruct(field.StructRef)
+	if err != nil {
+		return fmt.Sprintf("has items whose fields cannot be found: %s", err)
+	}
+
+	for _, name := range []string{keyValue.Key, keyValue.Value} {
+		item, found := lo.Find(items, func(item *projscan.Field) bool { return item.Name == name })
+
+		switch {
+		case !found:
+			return fmt.Sprintf("has items without a %s field", name)
+		case item.Type != projscan.FieldTypeString || item.TypeRef != nil || item.Pointer || item.Array:
+			return fmt.Sprintf("has items whose %s field is not a string", name)
+		}
+	}
+
+	return ""
+}
+
+// describeDeclarations lists the Go path and the file:line of each declaration, one per line.
+func (g *Generator) describeDeclarations(declarations []*FlagDeclaration) string {
+	var sb strings.Builder
+	for _, declaration := range declarations {
+		sb.WriteString("\n\t" + declaration.Path)
+
+		if position := g.position(declaration.Field.Pos); position.IsValid() {
+			sb.WriteString(fmt.Sprintf(" at %s:%d", position.Filename, position.Line))
+		}
+	}
+
+	return sb.String()
+}
+
+// position resolves the given position using the file set of the scanner.
+func (g *Generator) position(pos token.Pos) token.Position {
+	if g.fset == nil || !pos.IsValid() {
+		return token.Position{}
+	}
+
+	return g.fset.Position(pos)
+}
@@ -0,0 +1,142 @@
+package code
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_checkFlags(t *testing.T) {
+	if _, ok := os.LookupEnv("GOROOT"); !ok {
+		t.Setenv("GOROOT", runtime.GOROOT())
+	}
+
+	model, err := filepath.Abs("testdata/model")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		structName string
+		options    Options
+		want       string // Error returned by checkFlags, where %[1]s stands for the directory of the model package
+	}{
+		{
+			name:       "valid flags",
+			structName: "Sizes",
+		},
+		{
+			name:       "fields colliding on one flag",
+			structName: "CollidingFields",
+			want: `invalid flags found in "CollidingFields":
+flag "name" is declared more than once, by:
+	CollidingFields.Name at %[1]s/checks.go:5
+	CollidingFields.Alias at %[1]s/checks.go:6`,
+		},
+		{
+			name:       "embedded prefix colliding with a field",
+			structName: "CollidingPrefix",
+			want: `invalid flags found in "CollidingPrefix":
+flag "endpoint-host" is declared more than once, by:
+	CollidingPrefix.Endpoint.Host at %[1]s/checks.go:11
+	CollidingPrefix.EndpointHost at %[1]s/checks.go:18`,
+		},
+		{
+			name:       "reserved help flag",
+			structName: "ReservedHelp",
+			want: `invalid flags found in "ReservedHelp":
+flag "help" is reserved by pflag, but it is declared by:
+	ReservedHelp.Help at %[1]s/checks.go:23`,
+		},
+		{
+			name:       "invalid pflag struct tags",
+			structName: "InvalidTags",
+			want: `invalid flags found in "InvalidTags":
+flag "verbose" has unknown options ["hiden"] in its pflag struct tag, declared by:
+	InvalidTags.Verbose at %[1]s/checks.go:29
+shorthand "po" must be a single character, but it is declared by:
+	InvalidTags.Port at %[1]s/checks.go:28`,
+		},
+		{
+			name:       "malformed default values",
+			structName: "BadDefaults",
+			want: `invalid flags found in "BadDefaults":
+default value "128" of flag "port" is invalid: strconv.ParseInt: parsing "128": value out of range, declared by:
+	BadDefaults.Port at %[1]s/defaults.go:26
+default value "90" of flag "timeout" is invalid: time: missing unit in duration "90", declared by:
+	BadDefaults.Timeout at %[1]s/defaults.go:27
+default value "02/01/2024" of flag "since" is invalid: parsing time "02/01/2024" as "2006-01-02": cannot parse "02/01/2024" as "2006", declared by:
+	BadDefaults.Since at %[1]s/defaults.go:28
+default value "trace" of flag "level" is invalid: "trace" is not one of: debug, info, declared by:
+	BadDefaults.Level at %[1]s/defaults.go:29
+default value "80,\"443" of flag "ports" is invalid: parse error on line 1, column 8: extraneous or missing " in quoted-field, declared by:
+	BadDefaults.Ports at %[1]s/defaults.go:30
+default value "a=x" of flag "labels" is invalid: invalid value of key "a": strconv.ParseInt: parsing "x": invalid syntax, declared by:
+	BadDefaults.Labels at %[1]s/defaults.go:31
+default value "trace=1" of flag "levels" is invalid: key "trace" is not one of: debug, info, declared by:
+	BadDefaults.Levels at %[1]s/defaults.go:32`,
+		},
+		{
+			name:       "required flag with a default value",
+			structName: "RequiredDefault",
+			want: `invalid flags found in "RequiredDefault":
+flag "name" is required, so it cannot have a default value, declared by:
+	RequiredDefault.Name at %[1]s/required.go:19`,
+		},
+		{
+			name:       "hidden and deprecated flags with pflag",
+			structName: "Legacy",
+		},
+		{
+			name:       "hidden and deprecated flags with the flag backend",
+			structName: "Legacy",
+			options:    Options{Backend: FlagBackendName},
+			want: `invalid flags found in "Legacy":
+flag "name" uses ["hidden"], which the flag backend does not support, declared by:
+	Legacy.Name at %[1]s/marks.go:5
+flag "old" uses ["deprecated"], which the flag backend does not support, declared by:
+	Legacy.Old at %[1]s/marks.go:6
+flag "verbose" uses ["shorthand-deprecated"], which the flag backend does not support, declared by:
+	Legacy.Verbose at %[1]s/marks.go:7`,
+		},
+		{
+			name:       "deprecated flags with the cli backend",
+			structName: "Legacy",
+			options:    Options{Backend: CliBackendName},
+			want: `invalid flags found in "Legacy":
+flag "old" uses ["deprecated"], which the cli backend does not support, declared by:
+	Legacy.Old at %[1]s/marks.go:6
+flag "verbose" uses ["shorthand-deprecated"], which the cli backend does not support, declared by:
+	Legacy.Verbose at %[1]s/marks.go:7`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStruct(t, tt.structName, tt.options)
+			if tt.want == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, fmt.Sprintf(tt.want, model))
+		})
+	}
+}
+
+// checkStruct checks the flags declared by the given struct of the model package in testdata.
+func checkStruct(t *testing.T, structName string, options Options) error {
+	t.Helper()
+
+	g := newGenerator(options)
+	st, err := g.structs.FindStructByDirectoryAndName("testdata/model", structName)
+	require.NoError(t, err)
+
+	declarations, err := g.structDeclarations(st)
+	require.NoError(t, err)
+
+	return g.checkFlags(st, declarations)
+}
@@ -0,0 +1,218 @@
+package code
+
+import (
+	"path"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	changecase "github.com/ku/go-change-case"
+	"github.com/samber/lo"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// cliFlagTypes are the flag types declared with the flags of github.com/urfave/cli/v2 itself, such as cli.StringFlag
+// for string. The other flag types are declared with cli.GenericFlag, holding one of the generic values of
+// FlagSetValueStructs.
+var cliFlagTypes = map[string]bool{
+	"string": true, "bool": true, "int": true, "int64": true, "uint": true, "uint64": true, "float64": true,
+	"duration": true, "string/slice": true, "int/slice": true, "int64/slice": true, "float64/slice": true,
+}
+
+// CliBackend generates code for github.com/urfave/cli/v2. The flags are declared by a function returning them, to be
+// set to cli.App or cli.Command, and read from the cli.Context given to the actions, which is wrapped by a generated
+// adapter. Environment variables are declared with the flags, so cli reads them. Flags cannot be deprecated, so fields
+// asking for it are reported.
+type CliBackend struct{}
+
+func (c *CliBackend) Name() string {
+	return CliBackendName
+}
+
+func (c *CliBackend) FlagSet() *jen.Statement {
+	return jen.Op("*").Qual("github.com/urfave/cli/v2", "Context")
+}
+
+func (c *CliBackend) Param() string {
+	return "c"
+}
+
+func (c *CliBackend) Source() string {
+	return "context"
+}
+
+func (c *CliBackend) Registers() bool {
+	return false
+}
+
+func (c *CliBackend) Flags(st *projscan.Struct) *jen.Statement {
+	return jen.Id(CliContextAdapterName(st))
+}
+
+func (c *CliBackend) Wrap(st *projscan.Struct, flags jen.Code) *jen.Statement {
+	return jen.Id(CliContextAdapterName(st)).Values(jen.Dict{jen.Id("Context"): flags})
+}
+
+func (c *CliBackend) Marks(*SetterCall) []jen.Code {
+	return []jen.Code{}
+}
+
+func (c *CliBackend) Unsupported(field *projscan.Field) []string {
+	return deprecationTags(field)
+}
+
+func (c *CliBackend) Blocks(st *projscan.Struct, flags *orderedmap.OrderedMap[string, []*projscan.Field]) []Block {
+	types := adapterFlagTypes(flags)
+	blocks := []Block{&CliContextAdapterStruct{Struct: st, Types: types}}
+
+	for _, typ := range types {
+		if !cliFlagTypes[typ] {
+			return append(blocks, &FlagSetValueStructs{Struct: st})
+		}
+	}
+
+	return blocks
+}
+
+// CliFlagsConstructor declares the flags of the struct as github.com/urfave/cli/v2 flags, with the same names, usage
+// messages and defaults as the ones registered to pflag.
+type CliFlagsConstructor struct {
+	Struct    *projscan.Struct
+	Flags     *orderedmap.OrderedMap[string, []*projscan.Field]
+	EnvPrefix string
+	Optional  []string // Flags of the fields enclosed in structs behind pointers
+}
+
+func (c *CliFlagsConstructor) MethodName() string {
+	return changecase.Pascal(path.Join(c.Struct.Name, "flags"))
+}
+
+func (c *CliFlagsConstructor) Statement() *jen.Statement {
+	flags := make([]jen.Code, 0)
+
+	for pair := c.Flags.Oldest(); pair != nil; pair = pair.Next() {
+		prefix, fields := pair.Key, pair.Value
+		for _, field := range fields {
+			flags = append(flags, jen.Line().Add(c.Flag(&SetterCall{Prefix: prefix, EnvPrefix: c.EnvPrefix, Struct: c.Struct, Field: field, Optional: lo.Contains(c.Optional, flagName(prefix, field))})))
+		}
+	}
+
+	// one flag per line
+	flags = append(flags, jen.Line())
+
+	return jen.Func().Id(c.MethodName()).Params().Index().Qual("github.com/urfave/cli/v2", "Flag").Block(
+		jen.Return(jen.Index().Qual("github.com/urfave/cli/v2", "Flag").Values(flags...)),
+	)
+}
+
+// Flag returns the declaration of the flag registered by the given setter call, such as &cli.StringFlag{...}.
+func (c *CliFlagsConstructor) Flag(call *SetterCall) *jen.Statement {
+	fields := jen.Dict{
+		jen.Id("Name"): jen.Lit(call.Flag()),
+	}
+
+	if usage := call.Usage(); usage != "" {
+		fields[jen.Id("Usage")] = jen.Lit(usage)
+	}
+
+	if shorthand := call.Shorthand(); shorthand != "" {
+		fields[jen.Id("Aliases")] = jen.Index().String().Values(jen.Lit(shorthand))
+	}
+
+	if name := envVarOf(c.EnvPrefix, call.Flag(), call.Field); name != "" {
+		fields[jen.Id("EnvVars")] = jen.Index().String().Values(jen.Lit(name))
+	}
+
+	// cli would require the flags of optional fields even when their struct is not set
+	tag := FlagTagOf(call.Field)
+	if tag.Required && !call.Optional {
+		fields[jen.Id("Required")] = jen.True()
+	}
+
+	if tag.Hidden {
+		fields[jen.Id("Hidden")] = jen.True()
+	}
+
+	typ, ok := registeredFlagType(call.Field)
+	_, hasDefault := call.Field.Tag.Lookup(DefaultTag)
+
+	switch {
+	case !ok:
+		fields[jen.Id("Value")] = call.FlagValue()
+		typ = "generic"
+	case !cliFlagTypes[typ]:
+		fields[jen.Id("Value")] = flagSetValue(c.Struct, typ, call.DefaultValue())
+		typ = "generic"
+	case hasDefault && strings.HasSuffix(typ, "/slice"):
+		fields[jen.Id("Value")] = jen.Qual("github.com/urfave/cli/v2", changecase.Pascal(path.Join("new", typ))).Call(call.DefaultValue().Op("..."))
+	case hasDefault:
+		fields[jen.Id("Value")] = call.DefaultValue()
+	}
+
+	return jen.Op("&").Qual("github.com/urfave/cli/v2", changecase.Pascal(path.Join(typ, "flag"))).Values(fields)
+}
+
+// CliContextAdapterStruct adapts a cli.Context of github.com/urfave/cli/v2 to the methods of pflag.FlagSet called by
+// the flags builder. A flag is changed when it was set on the command line or by its environment variable, as told by
+// cli.Context.IsSet. Only the methods of the flag types in Types are generated.
+type CliContextAdapterStruct struct {
+	Struct *projscan.Struct
+	Types  []string // Flag types declared by the struct, as returned by flagType
+}
+
+// CliContextAdapterName returns the name of the cli context adapter generated for the given struct.
+func CliContextAdapterName(st *projscan.Struct) string {
+	return changecase.Camel(path.Join(st.Name, "cli", "context"))
+}
+
+func (a *CliContextAdapterStruct) Statement() *jen.Statement {
+	name := CliContextAdapterName(a.Struct)
+	receiver := jen.Id("c").Id(name)
+
+	statement := jen.Type().Id(name).Struct(
+		jen.Op("*").Qual("github.com/urfave/cli/v2", "Context"),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Changed").Params(jen.Id("name").String()).Bool().Block(
+		jen.Return(jen.Id("c").Dot("IsSet").Call(jen.Id("name"))),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Lookup").Params(jen.Id("name").String()).Op("*").Qual("flag", "Flag").Block(
+		jen.List(jen.Id("value"), jen.Id("ok")).Op(":=").Id("c").Dot("Generic").Call(jen.Id("name")).Assert(jen.Qual("flag", "Value")),
+		jen.If(jen.Op("!").Id("ok")).Block(
+			jen.Return(jen.Nil()),
+		),
+		jen.Comment("cli does not keep the default values, which are only known while the flags are not set"),
+		jen.Return(jen.Op("&").Qual("flag", "Flag").Values(jen.Dict{
+			jen.Id("Name"):     jen.Id("name"),
+			jen.Id("Value"):    jen.Id("value"),
+			jen.Id("DefValue"): jen.Id("value").Dot("String").Call(),
+		})),
+	)
+
+	for _, typ := range a.Types {
+		statement.Line().Line().Add(a.getter(receiver, typ))
+	}
+
+	return statement
+}
+
+// getter returns the method retrieving the flags of the given type, such as GetInt32 for int32.
+func (a *CliContextAdapterStruct) getter(receiver *jen.Statement, typ string) *jen.Statement {
+	method := changecase.Pascal(typ)
+	goType := adapterGoType(typ)
+
+	if cliFlagTypes[typ] {
+		return jen.Func().Params(receiver.Clone()).Id("Get"+method).Params(jen.Id("name").String()).Params(goType, jen.Error()).Block(
+			jen.Return().List(jen.Id("c").Dot(method).Call(jen.Id("name")), jen.Nil()),
+		)
+	}
+
+	return jen.Func().Params(receiver.Clone()).Id("Get"+method).Params(jen.Id("name").String()).Params(goType.Clone(), jen.Error()).Block(
+		jen.Id("value").Op(":=").Id("c").Dot("Value").Call(jen.Id("name")),
+		jen.List(jen.Id("typed"), jen.Id("ok")).Op(":=").Id("value").Assert(goType.Clone()),
+		jen.If(jen.Op("!").Id("ok")).Block(
+			jen.Return().List(jen.Id("typed"), jen.Qual("fmt", "Errorf").Call(jen.Lit("trying to get "+strings.ReplaceAll(typ, "/", " ")+" value of flag %s of type %T"), jen.Id("name"), jen.Id("value"))),
+		),
+		jen.Return().List(jen.Id("typed"), jen.Nil()),
+	)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/dave/jennifer/jen"
 	changecase "github.com/ku/go-change-case"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
 
 	"github.com/totvs-cloud/pflagstruct/projscan"
 )
@@ -12,6 +13,7 @@ import (
 type SetUpConstructor struct {
 	FlagsBuilderName string
 	Struct           *projscan.Struct
+	Backend          Backend
 }
 
 func (c *SetUpConstructor) MethodName() string {
@@ -20,31 +22,60 @@ func (c *SetUpConstructor) MethodName() string {
 
 func (c *SetUpConstructor) Statement() *jen.Statement {
 	args := []jen.Code{
-		jen.Id("flags").Op("*").Qual("github.com/spf13/pflag", "FlagSet"),
+		jen.Id(c.Backend.Param()).Add(c.Backend.FlagSet()),
 	}
 
 	methodCall := changecase.Camel(path.Join("setUp", c.Struct.Name))
 
 	return jen.Func().Id(c.MethodName()).Params(args...).Block(
 		jen.Parens(
-			jen.Op("&").Id(c.FlagsBuilderName).Values(jen.Id("flags").Op(":").Id("flags")),
+			flagsBuilder(c.Backend, c.FlagsBuilderName, c.Struct),
 		).
 			Dot(methodCall).Call(),
 	)
 }
 
+type SetUpWithDefaultsConstructor struct {
+	FlagsBuilderName string
+	Struct           *projscan.Struct
+	Backend          Backend
+}
+
+func (c *SetUpWithDefaultsConstructor) MethodName() string {
+	return changecase.Pascal(path.Join("SetUp", c.Struct.Name, "to", "flags", "with", "defaults"))
+}
+
+func (c *SetUpWithDefaultsConstructor) Statement() *jen.Statement {
+	args := []jen.Code{
+		jen.Id(c.Backend.Param()).Add(c.Backend.FlagSet()),
+		jen.Id("defaults").Op("*").Qual(c.Struct.Package.Path, c.Struct.Name),
+	}
+
+	methodCall := changecase.Camel(path.Join("setUp", c.Struct.Name, "with", "defaults"))
+
+	return jen.Func().Id(c.MethodName()).Params(args...).Block(
+		jen.Parens(
+			flagsBuilder(c.Backend, c.FlagsBuilderName, c.Struct),
+		).
+			Dot(methodCall).Call(jen.Id("defaults")),
+	)
+}
+
 type GetConstructor struct {
 	FlagsBuilderName string
 	Struct           *projscan.Struct
+	Backend          Backend
+	Required         bool // Indicates whether the struct has required flags, checked before reading any flag
+	Env              bool // Indicates whether flags fall back to environment variables, read before any flag
 }
 
 func (g *GetConstructor) MethodName() string {
-	return changecase.Pascal(path.Join("Get", g.Struct.Name, "from", "flags"))
+	return changecase.Pascal(path.Join("Get", g.Struct.Name, "from", g.Backend.Source()))
 }
 
 func (g *GetConstructor) Statement() *jen.Statement {
 	args := []jen.Code{
-		jen.Id("flags").Op("*").Qual("github.com/spf13/pflag", "FlagSet"),
+		jen.Id(g.Backend.Param()).Add(g.Backend.FlagSet()),
 	}
 	returns := []jen.Code{
 		jen.Op("*").Qual(g.Struct.Package.Path, g.Struct.Name),
@@ -54,9 +85,30 @@ func (g *GetConstructor) Statement() *jen.Statement {
 	structName := changecase.Camel(g.Struct.Name)
 	methodCall := changecase.Camel(path.Join("get", g.Struct.Name))
 
-	return jen.Func().Id(g.MethodName()).Params(args...).Params(returns...).Block(
+	calls := make([]jen.Code, 0)
+	if g.Env {
+		calls = append(calls, jen.If(
+			jen.Err().Op(":=").Parens(flagsBuilder(g.Backend, g.FlagsBuilderName, g.Struct)).
+				Dot((&EnvMethod{Struct: g.Struct}).MethodName()).Call(),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return().List(jen.Nil(), jen.Err()),
+		))
+	}
+
+	if g.Required {
+		calls = append(calls, jen.If(
+			jen.Err().Op(":=").Parens(flagsBuilder(g.Backend, g.FlagsBuilderName, g.Struct)).
+				Dot((&RequiredFlagsMethod{Struct: g.Struct}).MethodName()).Call(),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return().List(jen.Nil(), jen.Err()),
+		))
+	}
+
+	return jen.Func().Id(g.MethodName()).Params(args...).Params(returns...).Block(append(calls,
 		jen.If(jen.List(jen.Id(structName),
-			jen.Id("err")).Op(":=").Parens(jen.Op("&").Id(g.FlagsBuilderName).Values(jen.Id("flags").Op(":").Id("flags"))).Dot(methodCall).Call(),
+			jen.Id("err")).Op(":=").Parens(flagsBuilder(g.Backend, g.FlagsBuilderName, g.Struct)).Dot(methodCall).Call(),
 			jen.Id("err").Op("!=").Nil()).
 			Block(
 				jen.Return().List(jen.Nil(), jen.Id("err")),
@@ -65,6 +117,153 @@ func (g *GetConstructor) Statement() *jen.Statement {
 				jen.Return().List(jen.Id(structName), jen.Nil()),
 			),
 		jen.Return().List(jen.Id("new").Call(jen.Qual(g.Struct.Package.Path, g.Struct.Name)), jen.Nil()),
+	)...)
+}
+
+type ApplyConstructor struct {
+	FlagsBuilderName string
+	Struct           *projscan.Struct
+	Backend          Backend
+	Env              bool // Indicates whether flags fall back to environment variables, read before any flag
+}
+
+func (a *ApplyConstructor) MethodName() string {
+	if source := a.Backend.Source(); source != "flags" {
+		return changecase.Pascal(path.Join("Apply", a.Struct.Name, "flags", "from", source))
+	}
+
+	return changecase.Pascal(path.Join("Apply", a.Struct.Name, "flags"))
+}
+
+func (a *ApplyConstructor) Statement() *jen.Statement {
+	args := []jen.Code{
+		jen.Id(a.Backend.Param()).Add(a.Backend.FlagSet()),
+		jen.Id("target").Op("*").Qual(a.Struct.Package.Path, a.Struct.Name),
+	}
+
+	calls := make([]jen.Code, 0)
+	if a.Env {
+		calls = append(calls, jen.If(
+			jen.Err().Op(":=").Parens(flagsBuilder(a.Backend, a.FlagsBuilderName, a.Struct)).
+				Dot((&EnvMethod{Struct: a.Struct}).MethodName()).Call(),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Err()),
+		))
+	}
+
+	return jen.Func().Id(a.MethodName()).Params(args...).Error().Block(append(calls,
+		jen.Return(jen.Parens(
+			flagsBuilder(a.Backend, a.FlagsBuilderName, a.Struct),
+		).Dot((&ApplyMethod{Struct: a.Struct}).MethodName()).Call(jen.Id("target"))),
+	)...)
+}
+
+// ChangedFieldsConstructor lists the paths of the fields whose flags were set on the command line, to build field
+// masks or merge patches for partial updates.
+type ChangedFieldsConstructor struct {
+	FlagsBuilderName string
+	Struct           *projscan.Struct
+	Backend          Backend
+	Declarations     []*FlagDeclaration
+	JSON             bool // Lists the paths of the fields as encoded by encoding/json instead of their Go paths
+}
+
+func (c *ChangedFieldsConstructor) MethodName() string {
+	if c.JSON {
+		return changecase.Pascal(path.Join("Changed", c.Struct.Name)) + "JSONFieldsFrom" + changecase.Pascal(c.Backend.Source())
+	}
+
+	return changecase.Pascal(path.Join("Changed", c.Struct.Name, "fields", "from", c.Backend.Source()))
+}
+
+func (c *ChangedFieldsConstructor) Statement() *jen.Statement {
+	args := []jen.Code{
+		jen.Id(c.Backend.Param()).Add(c.Backend.FlagSet()),
+	}
+
+	fields := make([]jen.Code, 0, len(c.Declarations))
+	for _, declaration := range c.Declarations {
+		fieldPath, ok := declaration.GoPath(), true
+		if c.JSON {
+			fieldPath, ok = declaration.JSONPath()
+		}
+
+		if ok {
+			fields = append(fields, jen.Values(jen.Dict{
+				jen.Id("flag"): jen.Lit(declaration.Flag),
+				jen.Id("path"): jen.Lit(fieldPath),
+			}))
+		}
+	}
+
+	return jen.Func().Id(c.MethodName()).Params(args...).Index().String().Block(
+		jen.Id("cf").Op(":=").Add(flagsBuilder(c.Backend, c.FlagsBuilderName, c.Struct)),
+		jen.Id("changed").Op(":=").Make(jen.Index().String(), jen.Lit(0)),
+		jen.For(jen.List(jen.Id("_"), jen.Id("field")).Op(":=").Range().Index().Struct(jen.List(jen.Id("flag"), jen.Id("path")).String()).Values(fields...)).Block(
+			jen.If(jen.Id("cf").Dot("flags").Dot("Changed").Call(jen.Id("field").Dot("flag"))).Block(
+				jen.Id("changed").Op("=").Append(jen.Id("changed"), jen.Id("field").Dot("path")),
+			),
+		),
+		jen.Return(jen.Id("changed")),
 	)
+}
+
+type CompletionConstructor struct {
+	Struct *projscan.Struct
+	Flags  *orderedmap.OrderedMap[string, []*projscan.Field]
+}
+
+func (c *CompletionConstructor) MethodName() string {
+	return changecase.Pascal(path.Join("Register", c.Struct.Name, "flag", "completions"))
+}
+
+// EnumFlags returns the setter calls of the flags whose values are restricted to the constants of a named type.
+func (c *CompletionConstructor) EnumFlags() []*SetterCall {
+	calls := make([]*SetterCall, 0)
+
+	for pair := c.Flags.Oldest(); pair != nil; pair = pair.Next() {
+		prefix, fields := pair.Key, pair.Value
+		for _, field := range fields {
+			if KindOf(field) == FieldKindNative && field.TypeRef != nil && field.TypeRef.IsEnum() {
+				calls = append(calls, &SetterCall{Prefix: prefix, Struct: c.Struct, Field: field})
+			}
+		}
+	}
+
+	return calls
+}
+
+func (c *CompletionConstructor) Statement() *jen.Statement {
+	args := []jen.Code{
+		jen.Id("cmd").Op("*").Qual("github.com/spf13/cobra", "Command"),
+	}
+
+	calls := make([]jen.Code, 0)
+
+	for _, call := range c.EnumFlags() {
+		values := make([]jen.Code, 0, len(call.Field.TypeRef.Values))
+		for _, value := range call.Field.TypeRef.ValueList() {
+			values = append(values, jen.Lit(value))
+		}
+
+		completion := jen.Func().Params(
+			jen.Id("cmd").Op("*").Qual("github.com/spf13/cobra", "Command"),
+			jen.Id("args").Index().String(),
+			jen.Id("toComplete").String(),
+		).Params(jen.Index().String(), jen.Qual("github.com/spf13/cobra", "ShellCompDirective")).Block(
+			jen.Return().List(jen.Index().String().Values(values...), jen.Qual("github.com/spf13/cobra", "ShellCompDirectiveNoFileComp")),
+		)
+
+		calls = append(calls, jen.If(
+			jen.Err().Op(":=").Id("cmd").Dot("RegisterFlagCompletionFunc").Call(jen.Lit(call.Flag()), completion),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return().Qual("fmt", "Errorf").Call(jen.Lit("error registering the completion of \""+call.Flag()+"\": %w"), jen.Err()),
+		))
+	}
+
+	calls = append(calls, jen.Return().Nil())
 
+	return jen.Func().Id(c.MethodName()).Params(args...).Error().Block(calls...)
 }
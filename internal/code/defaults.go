@@ -0,0 +1,465 @@
+package code
+
+import (
+	"encoding/csv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// DefaultTag is the struct tag holding the default value of a flag, written as it would be on the command line.
+// Slices and maps take comma-separated values, such as `default:"a,b"` or `default:"key1=value1,key2=value2"`.
+const DefaultTag = "default"
+
+// durationUnits are the units used to write default durations, from the largest to the smallest.
+var durationUnits = []struct {
+	Name     string
+	Duration time.Duration
+}{
+	{Name: "Hour", Duration: time.Hour},
+	{Name: "Minute", Duration: time.Minute},
+	{Name: "Second", Duration: time.Second},
+	{Name: "Millisecond", Duration: time.Millisecond},
+	{Name: "Microsecond", Duration: time.Microsecond},
+	{Name: "Nanosecond", Duration: time.Nanosecond},
+}
+
+// DefaultValueOf parses the given default value for the flag of the field, returning the expression registered as the
+// default of the flag. It returns an error if the value is not valid for the field type.
+func DefaultValueOf(field *projscan.Field, value string) (*jen.Statement, error) {
+	switch KindOf(field) {
+	case FieldKindNative:
+		if field.Array {
+			values, err := splitDefault(value)
+			if err != nil {
+				return nil, err
+			}
+
+			elements := make([]jen.Code, 0, len(values))
+			for _, v := range values {
+				element, err := parseDefaultScalar(field, v)
+				if err != nil {
+					return nil, err
+				}
+
+				elements = append(elements, element)
+			}
+
+			return jen.Index().Id(sliceElementType(field.Type).String()).Values(elements...), nil
+		}
+
+		return parseDefaultScalar(field, value)
+	case FieldKindDuration:
+		if field.Array {
+			values, err := splitDefault(value)
+			if err != nil {
+				return nil, err
+			}
+
+			elements := make([]jen.Code, 0, len(values))
+			for _, v := range values {
+				element, err := parseDefaultDuration(v)
+				if err != nil {
+					return nil, err
+				}
+
+				elements = append(elements, element)
+			}
+
+			return jen.Index().Qual("time", "Duration").Values(elements...), nil
+		}
+
+		return parseDefaultDuration(value)
+	case FieldKindTime:
+		if field.Array {
+			values, err := splitDefault(value)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, v := range values {
+				if err = parseDefaultTime(field, v); err != nil {
+					return nil, err
+				}
+			}
+
+			return stringSliceStatement(values), nil
+		}
+
+		if err := parseDefaultTime(field, value); err != nil {
+			return nil, err
+		}
+
+		return jen.Lit(value), nil
+	case FieldKindStringMap, FieldKindKeyValue:
+		values, err := splitDefault(value)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range values {
+			key, val, ok := strings.Cut(v, "=")
+			if !ok {
+				return nil, errors.Errorf("%q is not a key-value pair such as key=value", v)
+			}
+
+			if err = parseDefaultMapPair(field, key, val); err != nil {
+				return nil, err
+			}
+		}
+
+		return stringSliceStatement(values), nil
+	case FieldKindValue:
+		return nil, errors.New("default values are not supported for types that parse flag values by themselves")
+	case FieldKindStructSlice:
+		return nil, errors.New("default values are not supported for slices of structs")
+	}
+
+	return nil, errors.Errorf("default values are not supported for fields of type %q", field.Type)
+}
+
+// splitDefault splits the comma-separated default value of a slice or map, following the CSV rules used by pflag so
+// that values holding commas can be quoted.
+func splitDefault(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return []string{}, nil
+	}
+
+	values, err := csv.NewReader(strings.NewReader(value)).Read()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return values, nil
+}
+
+// parseDefaultScalar parses a single default value of a field of a built-in type, checking it against the constants of
+// the named type of the field when it is an enumeration.
+func parseDefaultScalar(field *projscan.Field, value string) (*jen.Statement, error) {
+	var (
+		statement *jen.Statement
+		canonical = value
+	)
+
+	switch field.Type {
+	case projscan.FieldTypeString:
+		statement = jen.Lit(value)
+	case projscan.FieldTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		statement = jen.Lit(b)
+	case projscan.FieldTypeInt, projscan.FieldTypeInt8, projscan.FieldTypeInt16, projscan.FieldTypeInt32, projscan.FieldTypeInt64:
+		n, err := strconv.ParseInt(value, 0, bitSize(field.Type))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		statement, canonical = jen.Lit(int(n)), strconv.FormatInt(n, 10)
+	case projscan.FieldTypeUint, projscan.FieldTypeUint8, projscan.FieldTypeUint16, projscan.FieldTypeUint32, projscan.FieldTypeUint64:
+		n, err := strconv.ParseUint(value, 0, bitSize(field.Type))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		statement, canonical = jen.Lit(n), strconv.FormatUint(n, 10)
+		if n <= math.MaxInt64 {
+			statement = jen.Lit(int(n))
+		}
+	case projscan.FieldTypeFloat32, projscan.FieldTypeFloat64:
+		f, err := strconv.ParseFloat(value, bitSize(field.Type))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		statement = jen.Lit(f)
+	default:
+		return nil, errors.Errorf("default values are not supported for fields of type %q", field.Type)
+	}
+
+	if field.TypeRef != nil && field.TypeRef.IsEnum() && !lo.Contains(field.TypeRef.ValueList(), canonical) {
+		return nil, errors.Errorf("%q is not one of: %s", value, strings.Join(field.TypeRef.ValueList(), ", "))
+	}
+
+	return statement, nil
+}
+
+// parseDefaultDuration parses a default duration, written with the largest unit that divides it, as in
+// 90 * time.Second.
+func parseDefaultDuration(value string) (*jen.Statement, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if d == 0 {
+		return jen.Lit(0), nil
+	}
+
+	for _, unit := range durationUnits {
+		if d%unit.Duration == 0 {
+			return jen.Lit(int(d/unit.Duration)).Op("*").Qual("time", unit.Name), nil
+		}
+	}
+
+	return jen.Lit(int(d)), nil
+}
+
+// parseDefaultMapPair checks that a default key-value pair of a map can be converted to the types of the keys and the
+// values of the map. The items of maps holding slices of strings and the pairs of key-value structs need no conversion.
+func parseDefaultMapPair(field *projscan.Field, key, value string) error {
+	valueType, ok := StringMapValueOf(field)
+	if !ok {
+		return nil
+	}
+
+	if ref := field.MapKey.TypeRef; ref != nil && ref.IsEnum() && !lo.Contains(ref.ValueList(), key) {
+		return errors.Errorf("key %q is not one of: %s", key, strings.Join(ref.ValueList(), ", "))
+	}
+
+	var err error
+
+	switch valueType {
+	case StringSliceMapValue:
+	case projscan.DurationFieldType:
+		_, err = parseDefaultDuration(value)
+	default:
+		_, err = parseDefaultScalar(field.MapValue, value)
+	}
+
+	return errors.WithMessagef(err, "invalid value of key %q", key)
+}
+
+// parseDefaultTime checks that a default timestamp is written in the layout of the field.
+func parseDefaultTime(field *projscan.Field, value string) error {
+	layout := TimeLayoutOf(field)
+	if layout.Unix() {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}
+
+	if _, err := time.Parse(layout.Layout, value); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// stringSliceStatement returns a []string literal holding the given values.
+func stringSliceStatement(values []string) *jen.Statement {
+	elements := make([]jen.Code, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, jen.Lit(v))
+	}
+
+	return jen.Index().String().Values(elements...)
+}
+
+// bitSize returns the size in bits of the given numeric type, as expected by the strconv parsing functions.
+func bitSize(fieldType projscan.FieldType) int {
+	switch fieldType {
+	case projscan.FieldTypeInt8, projscan.FieldTypeUint8:
+		return 8
+	case projscan.FieldTypeInt16, projscan.FieldTypeUint16:
+		return 16
+	case projscan.FieldTypeInt32, projscan.FieldTypeUint32, projscan.FieldTypeFloat32:
+		return 32
+	case projscan.FieldTypeInt64, projscan.FieldTypeUint64, projscan.FieldTypeFloat64:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// RuntimeDefaultOf returns the statements that read the default value of the flag of the field from the given
+// expression, which selects the field in a struct holding the default values, along with the expression registered as
+// the default of the flag. Nil pointers fall back to the zero value. For fields whose type parses flag values by
+// itself, the returned expression is a pointer to a copy of the value.
+func RuntimeDefaultOf(field *projscan.Field, source *jen.Statement, name string) ([]jen.Code, *jen.Statement) {
+	switch KindOf(field) {
+	case FieldKindNative, FieldKindDuration:
+		if field.Array {
+			if !field.ArrayPointer && !needsConversion(field) {
+				return []jen.Code{}, source
+			}
+
+			element := flagGoType(sliceElementType(field.Type))
+			statements := []jen.Code{jen.Var().Id(name).Index().Add(element)}
+
+			return append(statements, guardedDefault(field, source, func(value *jen.Statement) []jen.Code {
+				if !needsConversion(field) {
+					return []jen.Code{jen.Id(name).Op("=").Add(value)}
+				}
+
+				// sized unsigned integers are widened to uint, which every one of them fits in
+				return []jen.Code{
+					jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Add(value)).Block(
+						jen.Id(name).Op("=").Append(jen.Id(name), element.Clone().Call(jen.Id("item"))),
+					),
+				}
+			})...), jen.Id(name)
+		}
+
+		convert := func(value *jen.Statement) *jen.Statement {
+			if needsConversion(field) {
+				return flagGoType(field.Type).Call(value)
+			}
+
+			return value
+		}
+
+		if !field.Pointer {
+			return []jen.Code{}, convert(source)
+		}
+
+		statements := []jen.Code{jen.Var().Id(name).Add(flagGoType(field.Type))}
+
+		return append(statements, guardedDefault(field, source, func(value *jen.Statement) []jen.Code {
+			return []jen.Code{jen.Id(name).Op("=").Add(convert(value))}
+		})...), jen.Id(name)
+	case FieldKindTime:
+		if field.Array {
+			appended := jen.Id(name).Op("=").Append(jen.Id(name), formatTime(field, jen.Id("item")))
+			if field.Pointer {
+				appended = jen.If(jen.Id("item").Op("!=").Nil()).Block(appended)
+			}
+
+			statements := []jen.Code{jen.Var().Id(name).Index().String()}
+
+			return append(statements, guardedDefault(field, source, func(value *jen.Statement) []jen.Code {
+				return []jen.Code{jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Add(value)).Block(appended)}
+			})...), jen.Id(name)
+		}
+
+		// zero timestamps leave the flag empty, as the getter does not assign empty flags
+		condition := jen.Op("!").Add(source.Clone()).Dot("IsZero").Call()
+		if field.Pointer {
+			condition = source.Clone().Op("!=").Nil().Op("&&").Add(condition)
+		}
+
+		return []jen.Code{
+			jen.Var().Id(name).String(),
+			jen.If(condition).Block(jen.Id(name).Op("=").Add(formatTime(field, source.Clone()))),
+		}, jen.Id(name)
+	case FieldKindStringMap:
+		statements := []jen.Code{jen.Var().Id(name).Index().String()}
+		statements = append(statements, guardedDefault(field, source, func(value *jen.Statement) []jen.Code {
+			return []jen.Code{
+				jen.For(jen.List(jen.Id("key"), jen.Id("value")).Op(":=").Range().Add(value)).Block(mapPairs(field, name)),
+			}
+		})...)
+
+		// maps are iterated in random order, so the pairs are sorted to keep the usage message stable
+		if valueType, _ := StringMapValueOf(field); valueType == StringSliceMapValue {
+			// by key only, keeping the items of each key in the order of their slice
+			key := func(i string) *jen.Statement {
+				return jen.List(jen.Id("k"+i), jen.Id("_"), jen.Id("_")).Op(":=").Qual("strings", "Cut").Call(jen.Id(name).Index(jen.Id(i)), jen.Lit("="))
+			}
+
+			return append(statements, jen.Qual("sort", "SliceStable").Call(jen.Id(name), jen.Func().Params(jen.List(jen.Id("i"), jen.Id("j")).Int()).Bool().Block(
+				key("i"), key("j"), jen.Return(jen.Id("ki").Op("<").Id("kj")),
+			))), jen.Id(name)
+		}
+
+		return append(statements, jen.Qual("sort", "Strings").Call(jen.Id(name))), jen.Id(name)
+	case FieldKindKeyValue:
+		keyValue, _ := KeyValueOf(field)
+		appended := jen.Id(name).Op("=").Append(jen.Id(name), jen.Id("tag").Dot(keyValue.Key).Op("+").Lit("=").Op("+").Id("tag").Dot(keyValue.Value))
+		if field.Pointer {
+			appended = jen.If(jen.Id("tag").Op("!=").Nil()).Block(appended)
+		}
+
+		statements := []jen.Code{jen.Var().Id(name).Index().String()}
+
+		return append(statements, guardedDefault(field, source, func(value *jen.Statement) []jen.Code {
+			return []jen.Code{jen.For(jen.List(jen.Id("_"), jen.Id("tag")).Op(":=").Range().Add(value)).Block(appended)}
+		})...), jen.Id(name)
+	case FieldKindStructSlice:
+		// items are given to the flag as JSON objects, which the getter reads back
+		appended := jen.If(jen.List(jen.Id("encoded"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(jen.Id("item")), jen.Err().Op("==").Nil()).Block(
+			jen.Id(name).Op("=").Append(jen.Id(name), jen.String().Call(jen.Id("encoded"))),
+		)
+		if field.Pointer {
+			appended = jen.If(jen.Id("item").Op("!=").Nil()).Block(appended)
+		}
+
+		statements := []jen.Code{jen.Var().Id(name).Index().String()}
+
+		return append(statements, guardedDefault(field, source, func(value *jen.Statement) []jen.Code {
+			return []jen.Code{jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Add(value)).Block(appended)}
+		})...), jen.Id(name)
+	case FieldKindValue:
+		statements := []jen.Code{jen.Id(name).Op(":=").New(convertedType(field))}
+
+		return append(statements, guardedDefault(field, source, func(value *jen.Statement) []jen.Code {
+			return []jen.Code{jen.Op("*").Id(name).Op("=").Add(value)}
+		})...), jen.Id(name)
+	}
+
+	return []jen.Code{}, jen.Nil()
+}
+
+// guardedDefault returns the given statements reading the default value of the field, dereferenced and guarded by a
+// nil check when the field is a pointer or a pointer to a slice.
+func guardedDefault(field *projscan.Field, source *jen.Statement, statements func(value *jen.Statement) []jen.Code) []jen.Code {
+	if !field.ArrayPointer && (field.Array || !field.Pointer) {
+		return statements(source.Clone())
+	}
+
+	return []jen.Code{
+		jen.If(source.Clone().Op("!=").Nil()).Block(statements(jen.Op("*").Add(source.Clone()))...),
+	}
+}
+
+// formatTime returns the expression formatting the given timestamp in the layout of the field.
+func formatTime(field *projscan.Field, value *jen.Statement) *jen.Statement {
+	layout := TimeLayoutOf(field)
+	if layout.Unix() {
+		return jen.Qual("strconv", "FormatInt").Call(value.Dot("Unix").Call(), jen.Lit(10))
+	}
+
+	return value.Dot("Format").Call(layout.Statement())
+}
+
+// mapPairs returns the statement appending the key-value pairs of the map entry held by key and value to the slice
+// named name, one pair per item for maps holding slices of strings, formatted as the getter parses them back.
+func mapPairs(field *projscan.Field, name string) *jen.Statement {
+	key := jen.Id("key")
+	if field.MapKey.TypeRef != nil {
+		key = jen.String().Call(key)
+	}
+
+	pair := func(value *jen.Statement) *jen.Statement {
+		return jen.Id(name).Op("=").Append(jen.Id(name), key.Clone().Op("+").Lit("=").Op("+").Add(value))
+	}
+
+	switch valueType, _ := StringMapValueOf(field); {
+	case valueType == projscan.FieldTypeString && field.MapValue.TypeRef == nil:
+		return pair(jen.Id("value"))
+	case valueType == StringSliceMapValue:
+		return jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Id("value")).Block(pair(jen.Id("item")))
+	default:
+		return pair(jen.Qual("fmt", "Sprint").Call(jen.Id("value")))
+	}
+}
+
+// flagGoType returns the Go type of the values held by the pflag flag of the given type.
+func flagGoType(fieldType projscan.FieldType) *jen.Statement {
+	if fieldType == projscan.DurationFieldType {
+		return jen.Qual("time", "Duration")
+	}
+
+	return jen.Id(fieldType.String())
+}
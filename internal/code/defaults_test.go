@@ -0,0 +1,76 @@
+package code
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+func TestDefaultValueOf(t *testing.T) {
+	if _, ok := os.LookupEnv("GOROOT"); !ok {
+		t.Setenv("GOROOT", runtime.GOROOT())
+	}
+
+	valid, malformed, catalog := structFields(t, "Defaults"), structFields(t, "BadDefaults"), structFields(t, "Catalog")
+
+	tests := []struct {
+		name    string
+		field   *projscan.Field
+		want    string
+		wantErr string
+	}{
+		{name: "int", field: valid["Port"], want: `127`},
+		// slices of sized unsigned integers are registered as []uint
+		{name: "slice of uint16", field: valid["Ports"], want: `[]uint{80, 443}`},
+		{name: "duration", field: valid["Timeout"], want: `90 * time.Second`},
+		{name: "slice of durations", field: valid["Backoffs"], want: `[]time.Duration{1 * time.Millisecond, 2 * time.Hour}`},
+		{name: "time", field: valid["Since"], want: `"2024-01-02"`},
+		{name: "enumeration", field: valid["Level"], want: `"info"`},
+		{name: "map", field: valid["Labels"], want: `[]string{"a=1", "b=2"}`},
+		{name: "map with named keys", field: catalog["Prices"], want: `[]string{"free=0", "standard=9.5"}`},
+		{name: "int overflow", field: malformed["Port"], wantErr: `strconv.ParseInt: parsing "128": value out of range`},
+		{name: "duration without unit", field: malformed["Timeout"], wantErr: `time: missing unit in duration "90"`},
+		{name: "time in another layout", field: malformed["Since"], wantErr: `parsing time "02/01/2024" as "2006-01-02": cannot parse "02/01/2024" as "2006"`},
+		{name: "value out of the enumeration", field: malformed["Level"], wantErr: `"trace" is not one of: debug, info`},
+		{name: "unterminated quote", field: malformed["Ports"], wantErr: `parse error on line 1, column 8: extraneous or missing " in quoted-field`},
+		{name: "map value of another type", field: malformed["Labels"], wantErr: `invalid value of key "a": strconv.ParseInt: parsing "x": invalid syntax`},
+		{name: "map key out of the enumeration", field: malformed["Levels"], wantErr: `key "trace" is not one of: debug, info`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DefaultValueOf(tt.field, tt.field.Tag.Get(DefaultTag))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, fmt.Sprintf("%#v", got))
+		})
+	}
+}
+
+// structFields returns the fields of the given struct of the model package in testdata, by name.
+func structFields(t *testing.T, structName string) map[string]*projscan.Field {
+	t.Helper()
+
+	g := newGenerator(Options{})
+	st, err := g.structs.FindStructByDirectoryAndName("testdata/model", structName)
+	require.NoError(t, err)
+
+	fields, err := g.fields.FindFieldsByStruct(st)
+	require.NoError(t, err)
+
+	byName := make(map[string]*projscan.Field, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	return byName
+}
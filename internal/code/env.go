@@ -0,0 +1,89 @@
+package code
+
+import (
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/dave/jennifer/jen"
+	changecase "github.com/ku/go-change-case"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// EnvTag is the struct tag holding the name of the environment variable read when the flag of the field is not set,
+// as in `env:"APP_TOKEN"`. `env:"-"` disables the environment variable given by the --env-prefix option.
+const EnvTag = "env"
+
+// envVarOf returns the environment variable read when the given flag is not set: the name in the `env` struct tag of
+// the field or, when the prefix is not empty, the flag name in upper snake case following the prefix, such as
+// APP_QUUX_NAME for --quux-name. It returns an empty string if the flag has no environment variable.
+func envVarOf(prefix, flag string, field *projscan.Field) string {
+	if name, ok := field.Tag.Lookup(EnvTag); ok {
+		if name = strings.TrimSpace(name); name == "-" {
+			return ""
+		}
+
+		return name
+	}
+
+	if prefix == "" {
+		return ""
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+
+		return '_'
+	}, flag)
+
+	return strings.TrimSuffix(prefix, "_") + "_" + name
+}
+
+// EnvMethod sets the flags that were not set on the command line from their environment variables, parsing the values
+// as the command line does. The flags are then seen as changed by the getters and by the required flags check.
+type EnvMethod struct {
+	FlagsBuilderName string
+	Struct           *projscan.Struct
+	Declarations     []*FlagDeclaration
+	Prefix           string
+}
+
+func (e *EnvMethod) MethodName() string {
+	return changecase.Camel(path.Join("lookup", e.Struct.Name, "env"))
+}
+
+// Variables returns the flags that have an environment variable, along with their variables.
+func (e *EnvMethod) Variables() []jen.Code {
+	variables := make([]jen.Code, 0)
+	for _, declaration := range e.Declarations {
+		if name := envVarOf(e.Prefix, declaration.Flag, declaration.Field); name != "" {
+			variables = append(variables, jen.Values(jen.Dict{
+				jen.Id("flag"): jen.Lit(declaration.Flag),
+				jen.Id("name"): jen.Lit(name),
+			}))
+		}
+	}
+
+	return variables
+}
+
+func (e *EnvMethod) Statement() *jen.Statement {
+	receiver := jen.Id("cf").Op("*").Id(e.FlagsBuilderName)
+
+	return jen.Func().Params(receiver).Id(e.MethodName()).Params().Error().Block(
+		jen.For(jen.List(jen.Id("_"), jen.Id("env")).Op(":=").Range().Index().Struct(jen.List(jen.Id("flag"), jen.Id("name")).String()).Values(e.Variables()...)).Block(
+			jen.If(jen.Id("cf").Dot("flags").Dot("Changed").Call(jen.Id("env").Dot("flag"))).Block(
+				jen.Continue(),
+			),
+			jen.If(jen.List(jen.Id("value"), jen.Id("ok")).Op(":=").Qual("os", "LookupEnv").Call(jen.Id("env").Dot("name")), jen.Id("ok")).Block(
+				jen.If(jen.Err().Op(":=").Id("cf").Dot("flags").Dot("Set").Call(jen.Id("env").Dot("flag"), jen.Id("value")), jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving %q from environment variable %s: %w"), jen.Id("env").Dot("flag"), jen.Id("env").Dot("name"), jen.Err())),
+				),
+			),
+		),
+		jen.Return(jen.Nil()),
+	)
+}
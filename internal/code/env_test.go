@@ -0,0 +1,34 @@
+package code
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+func TestEnvVarOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		flag   string
+		tag    reflect.StructTag
+		want   string
+	}{
+		{name: "no prefix", flag: "host", want: ""},
+		{name: "prefix", prefix: "APP", flag: "host", want: "APP_HOST"},
+		{name: "prefix ending with an underscore", prefix: "APP_", flag: "host", want: "APP_HOST"},
+		{name: "nested flag", prefix: "APP", flag: "proxy-url", want: "APP_PROXY_URL"},
+		{name: "tag without prefix", flag: "token", tag: `env:"SERVICE_TOKEN"`, want: "SERVICE_TOKEN"},
+		{name: "tag replacing the prefix", prefix: "APP", flag: "token", tag: `env:"SERVICE_TOKEN"`, want: "SERVICE_TOKEN"},
+		{name: "disabled", prefix: "APP", flag: "debug", tag: `env:"-"`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, envVarOf(tt.prefix, tt.flag, &projscan.Field{Tag: tt.tag}))
+		})
+	}
+}
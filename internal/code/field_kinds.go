@@ -5,19 +5,35 @@ package code
 import "github.com/totvs-cloud/pflagstruct/projscan"
 
 // FieldKind
-// ENUM(Native,StdLib,StringMap,TCloudTag,Struct)
+// ENUM(Native,Duration,Time,Value,StdLib,StringMap,KeyValue,StructSlice,Struct)
 type FieldKind string
 
 func KindOf(field *projscan.Field) FieldKind {
-	if field.IsTCloudTags() {
-		return FieldKindTCloudTag
+	if FlagTagOf(field).Skip {
+		return ""
+	}
+
+	if _, ok := KeyValueOf(field); ok && field.StructRef != nil && field.Array {
+		return FieldKindKeyValue
+	}
+
+	if field.IsDuration() {
+		return FieldKindDuration
+	}
+
+	if field.IsTime() {
+		return FieldKindTime
+	}
+
+	if field.IsFlagValue() && !field.Array {
+		return FieldKindValue
 	}
 
 	if field.Type.IsValid() {
 		return FieldKindNative
 	}
 
-	if field.Type == "map[string]string" {
+	if _, ok := StringMapValueOf(field); ok {
 		return FieldKindStringMap
 	}
 
@@ -25,9 +41,44 @@ func KindOf(field *projscan.Field) FieldKind {
 		return FieldKindStdLib
 	}
 
-	if field.StructRef != nil && !field.Array {
+	if field.StructRef != nil && field.Array {
+		return FieldKindStructSlice
+	}
+
+	if field.StructRef != nil {
 		return FieldKindStruct
 	}
 
 	return ""
 }
+
+// StringSliceMapValue is the type of the values of the maps holding slices of strings, such as headers.
+const StringSliceMapValue projscan.FieldType = "[]string"
+
+// StringMapValueOf returns the type of the values of the given map keyed by strings, such as int64 for
+// map[string]int64 or StringSliceMapValue for map[string][]string. Named keys and values are typed after their
+// underlying type, so map[Region]Tier gives string when both are declared as strings. It returns false for any other
+// type, including the maps of pointers, of structs or of types parsing flag values by themselves.
+func StringMapValueOf(field *projscan.Field) (projscan.FieldType, bool) {
+	key, value := field.MapKey, field.MapValue
+	if key == nil || value == nil || field.Array {
+		return "", false
+	}
+
+	if key.Type != projscan.FieldTypeString || key.Pointer || key.Array || key.IsFlagValue() {
+		return "", false
+	}
+
+	switch {
+	case value.Pointer, value.IsFlagValue():
+		return "", false
+	case value.Array:
+		if value.Type == projscan.FieldTypeString && value.TypeRef == nil {
+			return StringSliceMapValue, true
+		}
+	case value.IsDuration(), value.Type.IsValid():
+		return value.Type, true
+	}
+
+	return "", false
+}
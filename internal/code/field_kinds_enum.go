@@ -14,12 +14,20 @@ import (
 const (
 	// FieldKindNative is a FieldKind of type Native.
 	FieldKindNative FieldKind = "Native"
+	// FieldKindDuration is a FieldKind of type Duration.
+	FieldKindDuration FieldKind = "Duration"
+	// FieldKindTime is a FieldKind of type Time.
+	FieldKindTime FieldKind = "Time"
+	// FieldKindValue is a FieldKind of type Value.
+	FieldKindValue FieldKind = "Value"
 	// FieldKindStdLib is a FieldKind of type StdLib.
 	FieldKindStdLib FieldKind = "StdLib"
 	// FieldKindStringMap is a FieldKind of type StringMap.
 	FieldKindStringMap FieldKind = "StringMap"
-	// FieldKindTCloudTag is a FieldKind of type TCloudTag.
-	FieldKindTCloudTag FieldKind = "TCloudTag"
+	// FieldKindKeyValue is a FieldKind of type KeyValue.
+	FieldKindKeyValue FieldKind = "KeyValue"
+	// FieldKindStructSlice is a FieldKind of type StructSlice.
+	FieldKindStructSlice FieldKind = "StructSlice"
 	// FieldKindStruct is a FieldKind of type Struct.
 	FieldKindStruct FieldKind = "Struct"
 )
@@ -39,11 +47,15 @@ func (x FieldKind) IsValid() bool {
 }
 
 var _FieldKindValue = map[string]FieldKind{
-	"Native":    FieldKindNative,
-	"StdLib":    FieldKindStdLib,
-	"StringMap": FieldKindStringMap,
-	"TCloudTag": FieldKindTCloudTag,
-	"Struct":    FieldKindStruct,
+	"Native":      FieldKindNative,
+	"Duration":    FieldKindDuration,
+	"Time":        FieldKindTime,
+	"Value":       FieldKindValue,
+	"StdLib":      FieldKindStdLib,
+	"StringMap":   FieldKindStringMap,
+	"KeyValue":    FieldKindKeyValue,
+	"StructSlice": FieldKindStructSlice,
+	"Struct":      FieldKindStruct,
 }
 
 // ParseFieldKind attempts to convert a string to a FieldKind.
@@ -1,11 +1,29 @@
 package code
 
 import (
-	changecase "github.com/ku/go-change-case"
 	"github.com/totvs-cloud/pflagstruct/projscan"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 )
 
+// EmbedTag is the struct tag that keeps the fields of an embedded struct under a prefix when set to "prefix".
+const EmbedTag = "embed"
+
+// flagPrefixOf returns the prefix of the flags registered for the fields of the given struct field, nested in a struct
+// whose flags are prefixed by parent. The prefix is the full path of the field, or only its own segment when the
+// generator is set up with short prefixes, both honouring the name in its `pflag` struct tag. The fields of embedded
+// structs are promoted to the parent's flag namespace, as Go does, unless the field is tagged with `embed:"prefix"`.
+func (g *Generator) flagPrefixOf(parent string, field *projscan.Field) string {
+	if field.Embedded && field.Tag.Get(EmbedTag) != "prefix" {
+		return parent
+	}
+
+	if g.options.ShortPrefixes {
+		return flagSegment(field)
+	}
+
+	return flagName(parent, field)
+}
+
 func (g *Generator) structFlags(st *projscan.Struct) (*orderedmap.OrderedMap[string, []*projscan.Field], error) {
 	flds, err := g.fields.FindFieldsByStruct(st)
 	if err != nil {
@@ -16,11 +34,11 @@ func (g *Generator) structFlags(st *projscan.Struct) (*orderedmap.OrderedMap[str
 
 	for _, fld := range flds {
 		switch KindOf(fld) {
-		case FieldKindNative, FieldKindTCloudTag, FieldKindStringMap:
+		case FieldKindNative, FieldKindDuration, FieldKindTime, FieldKindValue, FieldKindKeyValue, FieldKindStringMap, FieldKindStructSlice:
 			fields, _ := refs.Get("")
 			refs.Set("", append(fields, fld))
 		case FieldKindStruct:
-			extracted, err := g.fieldFlags(fld, changecase.Param(fld.Name))
+			extracted, err := g.fieldFlags(fld, g.flagPrefixOf("", fld))
 			if err != nil {
 				return nil, err
 			}
@@ -30,9 +48,10 @@ func (g *Generator) structFlags(st *projscan.Struct) (*orderedmap.OrderedMap[str
 			for pair := refs.Oldest(); pair != nil; pair = pair.Next() {
 				merged.Set(pair.Key, pair.Value)
 			}
-			// Merge extracted into merged map
+			// Merge extracted into merged map, appending the flags of promoted fields to the existing ones
 			for pair := extracted.Oldest(); pair != nil; pair = pair.Next() {
-				merged.Set(pair.Key, pair.Value)
+				fields, _ := merged.Get(pair.Key)
+				merged.Set(pair.Key, append(fields, pair.Value...))
 			}
 
 			refs = merged
@@ -52,11 +71,11 @@ func (g *Generator) fieldFlags(field *projscan.Field, prefix string) (*orderedma
 
 	for _, fld := range flds {
 		switch KindOf(fld) {
-		case FieldKindNative, FieldKindTCloudTag, FieldKindStringMap:
+		case FieldKindNative, FieldKindDuration, FieldKindTime, FieldKindValue, FieldKindKeyValue, FieldKindStringMap, FieldKindStructSlice:
 			fields, _ := refs.Get(prefix)
 			refs.Set(prefix, append(fields, fld))
 		case FieldKindStruct:
-			extracted, err := g.fieldFlags(fld, changecase.Param(fld.Name))
+			extracted, err := g.fieldFlags(fld, g.flagPrefixOf(prefix, fld))
 			if err != nil {
 				return nil, err
 			}
@@ -66,9 +85,10 @@ func (g *Generator) fieldFlags(field *projscan.Field, prefix string) (*orderedma
 			for pair := refs.Oldest(); pair != nil; pair = pair.Next() {
 				merged.Set(pair.Key, pair.Value)
 			}
-			// Merge extracted into merged map
+			// Merge extracted into merged map, appending the flags of promoted fields to the existing ones
 			for pair := extracted.Oldest(); pair != nil; pair = pair.Next() {
-				merged.Set(pair.Key, pair.Value)
+				fields, _ := merged.Get(pair.Key)
+				merged.Set(pair.Key, append(fields, pair.Value...))
 			}
 
 			refs = merged
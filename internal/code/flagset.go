@@ -0,0 +1,337 @@
+package code
+
+import (
+	"path"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	changecase "github.com/ku/go-change-case"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// FlagSetAdapterStruct adapts a flag.FlagSet of the standard library to the methods of pflag.FlagSet called by the
+// flags builder. Every flag is registered with flag.Var, holding one of the generic values of FlagSetValueStructs.
+// Only the methods of the flag types in Types are generated.
+type FlagSetAdapterStruct struct {
+	Struct *projscan.Struct
+	Types  []string // Flag types registered by the struct, as returned by flagType
+}
+
+// FlagSetAdapterName returns the name of the flag set adapter generated for the given struct.
+func FlagSetAdapterName(st *projscan.Struct) string {
+	return changecase.Camel(path.Join(st.Name, "flag", "set"))
+}
+
+// adapterFlagTypes returns the flag types the given flags are registered with, in the order they are first registered.
+func adapterFlagTypes(flags *orderedmap.OrderedMap[string, []*projscan.Field]) []string {
+	types := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for pair := flags.Oldest(); pair != nil; pair = pair.Next() {
+		for _, field := range pair.Value {
+			typ, ok := registeredFlagType(field)
+			if ok && !seen[typ] {
+				seen[typ] = true
+				types = append(types, typ)
+			}
+		}
+	}
+
+	return types
+}
+
+// registeredFlagType returns the flag type the field is registered with, such as int32, string/slice for maps or
+// string/array for slices of structs. It returns false for fields whose type parses flag values by itself.
+func registeredFlagType(field *projscan.Field) (string, bool) {
+	switch KindOf(field) {
+	case FieldKindValue:
+		return "", false
+	case FieldKindKeyValue, FieldKindStringMap:
+		return path.Join(projscan.FieldTypeString.String(), "slice"), true
+	case FieldKindStructSlice:
+		return path.Join(projscan.FieldTypeString.String(), "array"), true
+	default:
+		return flagType(field), true
+	}
+}
+
+func (a *FlagSetAdapterStruct) Statement() *jen.Statement {
+	name := FlagSetAdapterName(a.Struct)
+	receiver := jen.Id("f").Id(name)
+
+	statement := jen.Type().Id(name).Struct(
+		jen.Op("*").Qual("flag", "FlagSet"),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Changed").Params(jen.Id("name").String()).Bool().Block(
+		jen.Id("registered").Op(":=").Id("f").Dot("Lookup").Call(jen.Id("name")),
+		jen.Id("changed").Op(":=").False(),
+		jen.Id("f").Dot("Visit").Call(jen.Func().Params(jen.Id("visited").Op("*").Qual("flag", "Flag")).Block(
+			jen.Id("changed").Op("=").Id("changed").Op("||").Id("registered").Op("!=").Nil().Op("&&").Id("visited").Dot("Value").Op("==").Id("registered").Dot("Value"),
+		)),
+		jen.Return(jen.Id("changed")),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("VarP").Params(
+		jen.Id("value").Qual("flag", "Value"),
+		jen.List(jen.Id("name"), jen.Id("shorthand"), jen.Id("usage")).String(),
+	).Block(
+		jen.Id("f").Dot("Var").Call(jen.Id("value"), jen.Id("name"), jen.Id("usage")),
+		jen.If(jen.Id("shorthand").Op("!=").Lit("")).Block(
+			jen.Id("f").Dot("Var").Call(jen.Id("value"), jen.Id("shorthand"), jen.Lit("shorthand for -").Op("+").Id("name")),
+		),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("get").Params(jen.Id("name").String()).Params(jen.Interface(), jen.Error()).Block(
+		jen.Id("registered").Op(":=").Id("f").Dot("Lookup").Call(jen.Id("name")),
+		jen.If(jen.Id("registered").Op("==").Nil()).Block(
+			jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("flag accessed but not defined: %s"), jen.Id("name"))),
+		),
+		jen.List(jen.Id("getter"), jen.Id("ok")).Op(":=").Id("registered").Dot("Value").Assert(jen.Qual("flag", "Getter")),
+		jen.If(jen.Op("!").Id("ok")).Block(
+			jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("trying to get the value of flag %s of type %T"), jen.Id("name"), jen.Id("registered").Dot("Value"))),
+		),
+		jen.Return().List(jen.Id("getter").Dot("Get").Call(), jen.Nil()),
+	)
+
+	for _, typ := range a.Types {
+		statement.Line().Line().Add(a.typeMethods(receiver, typ))
+	}
+
+	return statement
+}
+
+// typeMethods returns the methods registering and retrieving the flags of the given type, such as Int32, Int32P and
+// GetInt32 for int32.
+func (a *FlagSetAdapterStruct) typeMethods(receiver *jen.Statement, typ string) *jen.Statement {
+	method := changecase.Pascal(typ)
+	goType := adapterGoType(typ)
+
+	return jen.Func().Params(receiver.Clone()).Id(method).Params(
+		jen.Id("name").String(),
+		jen.Id("value").Add(goType.Clone()),
+		jen.Id("usage").String(),
+	).Block(
+		jen.Id("f").Dot(method+"P").Call(jen.Id("name"), jen.Lit(""), jen.Id("value"), jen.Id("usage")),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id(method+"P").Params(
+		jen.List(jen.Id("name"), jen.Id("shorthand")).String(),
+		jen.Id("value").Add(goType.Clone()),
+		jen.Id("usage").String(),
+	).Block(
+		jen.Id("f").Dot("VarP").Call(flagSetValue(a.Struct, typ, jen.Id("value")), jen.Id("name"), jen.Id("shorthand"), jen.Id("usage")),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Get"+method).Params(jen.Id("name").String()).Params(goType.Clone(), jen.Error()).Block(
+		jen.List(jen.Id("value"), jen.Err()).Op(":=").Id("f").Dot("get").Call(jen.Id("name")),
+		jen.List(jen.Id("typed"), jen.Id("ok")).Op(":=").Id("value").Assert(goType.Clone()),
+		jen.If(jen.Err().Op("==").Nil().Op("&&").Op("!").Id("ok")).Block(
+			jen.Err().Op("=").Qual("fmt", "Errorf").Call(jen.Lit("trying to get "+strings.ReplaceAll(typ, "/", " ")+" value of flag %s of type %T"), jen.Id("name"), jen.Id("value")),
+		),
+		jen.Return().List(jen.Id("typed"), jen.Err()),
+	)
+}
+
+// FlagSetValueStructs are the generic flag.Value types registered for the flag types missing from a flags package,
+// parsing the values as pflag does. Slices take comma-separated values, appended to each other when the flag is
+// repeated, while arrays take a single item each time the flag is repeated.
+type FlagSetValueStructs struct {
+	Struct *projscan.Struct
+}
+
+func flagSetValueName(st *projscan.Struct) string {
+	return changecase.Camel(path.Join(st.Name, "flag", "set", "value"))
+}
+
+func flagSetSliceValueName(st *projscan.Struct) string {
+	return changecase.Camel(path.Join(st.Name, "flag", "set", "slice", "value"))
+}
+
+func flagSetArrayValueName(st *projscan.Struct) string {
+	return changecase.Camel(path.Join(st.Name, "flag", "set", "array", "value"))
+}
+
+// flagSetValue returns the expression creating the generic flag.Value of the given flag type, holding the given value.
+func flagSetValue(st *projscan.Struct, typ string, value jen.Code) *jen.Statement {
+	element, name := typ, flagSetValueName(st)
+
+	switch {
+	case strings.HasSuffix(typ, "/slice"):
+		element, name = strings.TrimSuffix(typ, "/slice"), flagSetSliceValueName(st)
+	case strings.HasSuffix(typ, "/array"):
+		element, name = strings.TrimSuffix(typ, "/array"), flagSetArrayValueName(st)
+	}
+
+	return jen.Id(changecase.Camel(path.Join("new", name))).Index(adapterGoType(element)).Call(value, adapterParser(element))
+}
+
+func (v *FlagSetValueStructs) Statement() *jen.Statement {
+	return v.valueStatement().Line().Line().Add(v.sliceValueStatement())
+}
+
+// valueStatement returns the generic flag.Value holding a single value, which stands for a boolean flag when the value
+// is a bool, so that it can be set without an argument.
+func (v *FlagSetValueStructs) valueStatement() *jen.Statement {
+	name := flagSetValueName(v.Struct)
+	receiver := jen.Id("v").Op("*").Id(name).Index(jen.Id("T"))
+
+	return jen.Type().Id(name).Types(jen.Id("T").Any()).Struct(
+		jen.Id("value").Op("*").Id("T"),
+		jen.Id("parse").Func().Params(jen.String()).Params(jen.Id("T"), jen.Error()),
+	).Line().Line().
+		Func().Id(changecase.Camel(path.Join("new", name))).Types(jen.Id("T").Any()).Params(
+		jen.Id("value").Id("T"),
+		jen.Id("parse").Func().Params(jen.String()).Params(jen.Id("T"), jen.Error()),
+	).Op("*").Id(name).Index(jen.Id("T")).Block(
+		jen.Return(jen.Op("&").Id(name).Index(jen.Id("T")).Values(jen.Dict{
+			jen.Id("value"): jen.Op("&").Id("value"),
+			jen.Id("parse"): jen.Id("parse"),
+		})),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("String").Params().String().Block(
+		jen.If(jen.Id("v").Op("==").Nil().Op("||").Id("v").Dot("value").Op("==").Nil()).Block(
+			jen.Return(jen.Qual("fmt", "Sprint").Call(jen.Op("*").New(jen.Id("T")))),
+		),
+		jen.Return(jen.Qual("fmt", "Sprint").Call(jen.Op("*").Id("v").Dot("value"))),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Set").Params(jen.Id("s").String()).Error().Block(
+		jen.List(jen.Id("value"), jen.Err()).Op(":=").Id("v").Dot("parse").Call(jen.Id("s")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.Op("*").Id("v").Dot("value").Op("=").Id("value"),
+		jen.Return(jen.Nil()),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Get").Params().Interface().Block(
+		jen.Return(jen.Op("*").Id("v").Dot("value")),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("IsBoolFlag").Params().Bool().Block(
+		jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Interface().Parens(jen.Id("v").Dot("value")).Assert(jen.Op("*").Bool()),
+		jen.Return(jen.Id("ok")),
+	)
+}
+
+// sliceValueStatement returns the generic flag.Value holding a slice, along with the constructor of the arrays, which
+// hold a slice as well. The first time the flag is set its values replace the default ones, and are appended to them
+// afterwards.
+func (v *FlagSetValueStructs) sliceValueStatement() *jen.Statement {
+	name := flagSetSliceValueName(v.Struct)
+	receiver := jen.Id("v").Op("*").Id(name).Index(jen.Id("T"))
+
+	return jen.Type().Id(name).Types(jen.Id("T").Any()).Struct(
+		jen.Id("value").Op("*").Index().Id("T"),
+		jen.Id("parse").Func().Params(jen.String()).Params(jen.Id("T"), jen.Error()),
+		jen.Id("changed").Bool(),
+		jen.Id("whole").Bool().Comment("takes each value as a single item instead of comma-separated ones"),
+	).Line().Line().
+		Func().Id(changecase.Camel(path.Join("new", name))).Types(jen.Id("T").Any()).Params(
+		jen.Id("value").Index().Id("T"),
+		jen.Id("parse").Func().Params(jen.String()).Params(jen.Id("T"), jen.Error()),
+	).Op("*").Id(name).Index(jen.Id("T")).Block(
+		jen.Return(jen.Op("&").Id(name).Index(jen.Id("T")).Values(jen.Dict{
+			jen.Id("value"): jen.Op("&").Id("value"),
+			jen.Id("parse"): jen.Id("parse"),
+		})),
+	).Line().Line().
+		Func().Id(changecase.Camel(path.Join("new", flagSetArrayValueName(v.Struct)))).Types(jen.Id("T").Any()).Params(
+		jen.Id("value").Index().Id("T"),
+		jen.Id("parse").Func().Params(jen.String()).Params(jen.Id("T"), jen.Error()),
+	).Op("*").Id(name).Index(jen.Id("T")).Block(
+		jen.Return(jen.Op("&").Id(name).Index(jen.Id("T")).Values(jen.Dict{
+			jen.Id("value"): jen.Op("&").Id("value"),
+			jen.Id("parse"): jen.Id("parse"),
+			jen.Id("whole"): jen.True(),
+		})),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("String").Params().String().Block(
+		jen.If(jen.Id("v").Op("==").Nil().Op("||").Id("v").Dot("value").Op("==").Nil()).Block(
+			jen.Return(jen.Lit("[]")),
+		),
+		jen.Id("items").Op(":=").Make(jen.Index().String(), jen.Lit(0), jen.Len(jen.Op("*").Id("v").Dot("value"))),
+		jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Op("*").Id("v").Dot("value")).Block(
+			jen.Id("items").Op("=").Append(jen.Id("items"), jen.Qual("fmt", "Sprint").Call(jen.Id("item"))),
+		),
+		jen.Return(jen.Lit("[").Op("+").Qual("strings", "Join").Call(jen.Id("items"), jen.Lit(",")).Op("+").Lit("]")),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Set").Params(jen.Id("s").String()).Error().Block(
+		jen.Var().Id("records").Index().String(),
+		jen.Switch().Block(
+			jen.Case(jen.Id("v").Dot("whole")).Block(
+				jen.Id("records").Op("=").Index().String().Values(jen.Id("s")),
+			),
+			jen.Case(jen.Id("s").Op("!=").Lit("")).Block(
+				jen.Var().Err().Error(),
+				jen.If(jen.List(jen.Id("records"), jen.Err()).Op("=").Qual("encoding/csv", "NewReader").Call(jen.Qual("strings", "NewReader").Call(jen.Id("s"))).Dot("Read").Call(), jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Err()),
+				),
+			),
+		),
+		jen.Id("items").Op(":=").Make(jen.Index().Id("T"), jen.Lit(0), jen.Len(jen.Id("records"))),
+		jen.For(jen.List(jen.Id("_"), jen.Id("record")).Op(":=").Range().Id("records")).Block(
+			jen.List(jen.Id("item"), jen.Err()).Op(":=").Id("v").Dot("parse").Call(jen.Id("record")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.Id("items").Op("=").Append(jen.Id("items"), jen.Id("item")),
+		),
+		jen.If(jen.Id("v").Dot("changed")).Block(
+			jen.Id("items").Op("=").Append(jen.Op("*").Id("v").Dot("value"), jen.Id("items").Op("...")),
+		),
+		jen.List(jen.Op("*").Id("v").Dot("value"), jen.Id("v").Dot("changed")).Op("=").List(jen.Id("items"), jen.True()),
+		jen.Return(jen.Nil()),
+	).Line().Line().
+		Func().Params(receiver.Clone()).Id("Get").Params().Interface().Block(
+		jen.Return(jen.Op("*").Id("v").Dot("value")),
+	)
+}
+
+// adapterGoType returns the Go type of the values of the given flag type, such as time.Duration for duration or
+// []int32 for int32/slice and []string for string/array.
+func adapterGoType(typ string) *jen.Statement {
+	if element := strings.TrimSuffix(strings.TrimSuffix(typ, "/slice"), "/array"); element != typ {
+		return jen.Index().Add(adapterGoType(element))
+	}
+
+	if typ == "duration" {
+		return jen.Qual("time", "Duration")
+	}
+
+	return jen.Id(typ)
+}
+
+// adapterParser returns the function parsing the values of the given flag type as pflag does, accepting the base
+// prefixes of integers such as 0x.
+func adapterParser(element string) *jen.Statement {
+	goType := adapterGoType(element)
+
+	var body []jen.Code
+	switch fieldType := projscan.FieldType(element); fieldType {
+	case projscan.FieldTypeString:
+		body = []jen.Code{jen.Return().List(jen.Id("s"), jen.Nil())}
+	case projscan.FieldTypeBool:
+		body = []jen.Code{jen.Return(jen.Qual("strconv", "ParseBool").Call(jen.Id("s")))}
+	case projscan.FieldTypeInt64:
+		body = []jen.Code{jen.Return(jen.Qual("strconv", "ParseInt").Call(jen.Id("s"), jen.Lit(0), jen.Lit(64)))}
+	case projscan.FieldTypeUint64:
+		body = []jen.Code{jen.Return(jen.Qual("strconv", "ParseUint").Call(jen.Id("s"), jen.Lit(0), jen.Lit(64)))}
+	case projscan.FieldTypeFloat64:
+		body = []jen.Code{jen.Return(jen.Qual("strconv", "ParseFloat").Call(jen.Id("s"), jen.Lit(64)))}
+	case projscan.FieldTypeInt, projscan.FieldTypeInt8, projscan.FieldTypeInt16, projscan.FieldTypeInt32:
+		body = convertedParse(goType, jen.Qual("strconv", "ParseInt").Call(jen.Id("s"), jen.Lit(0), jen.Lit(bitSize(fieldType))))
+	case projscan.FieldTypeUint, projscan.FieldTypeUint8, projscan.FieldTypeUint16, projscan.FieldTypeUint32:
+		body = convertedParse(goType, jen.Qual("strconv", "ParseUint").Call(jen.Id("s"), jen.Lit(0), jen.Lit(bitSize(fieldType))))
+	case projscan.FieldTypeFloat32:
+		body = convertedParse(goType, jen.Qual("strconv", "ParseFloat").Call(jen.Id("s"), jen.Lit(32)))
+	default:
+		body = []jen.Code{jen.Return(jen.Qual("time", "ParseDuration").Call(jen.Id("s")))}
+	}
+
+	return jen.Func().Params(jen.Id("s").String()).Params(goType, jen.Error()).Block(body...)
+}
+
+// convertedParse returns the statements converting the number parsed by the given call to the given type. The call
+// parses to the bit size of the type, so numbers out of its range are reported by strconv rather than truncated.
+func convertedParse(goType *jen.Statement, parse *jen.Statement) []jen.Code {
+	return []jen.Code{
+		jen.List(jen.Id("n"), jen.Err()).Op(":=").Add(parse),
+		jen.Return().List(goType.Clone().Call(jen.Id("n")), jen.Err()),
+	}
+}
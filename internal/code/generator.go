@@ -2,12 +2,15 @@ package code
 
 import (
 	"fmt"
+	"go/token"
 	"path"
 
 	changecase "github.com/ku/go-change-case"
+	"github.com/pkg/errors"
 
 	"github.com/totvs-cloud/pflagstruct/internal/dir"
 	"github.com/totvs-cloud/pflagstruct/projscan"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
 )
 
 type Generator struct {
@@ -15,13 +18,63 @@ type Generator struct {
 	packages projscan.PackageFinder
 	projects projscan.ProjectFinder
 	structs  projscan.StructFinder
+	fset     *token.FileSet
+	options  Options
 }
 
-func NewGenerator(fields projscan.FieldFinder, packages projscan.PackageFinder, projects projscan.ProjectFinder, structs projscan.StructFinder) *Generator {
-	return &Generator{fields: fields, packages: packages, projects: projects, structs: structs}
+// Options tunes the code produced by the Generator.
+type Options struct {
+	ShortPrefixes bool   // Prefixes the flags of nested struct fields with the name of the innermost struct field only
+	EnvPrefix     string // Prefix of the environment variables read when flags are not set, such as APP for APP_NAME
+	Viper         bool   // Generates the functions that bind the flags to viper and build the struct from viper
+	Completions   bool   // Generates the function registering the values of enumerations as cobra shell completions
+	Backend       string // Name of the flags package the generated code is written for, pflag when empty
+}
+
+// Args returns the command line arguments that reproduce the options, to be appended to the go:generate directive.
+func (o Options) Args() string {
+	var args string
+	if o.ShortPrefixes {
+		args += " --short-prefixes"
+	}
+
+	if o.EnvPrefix != "" {
+		args += " --env-prefix " + o.EnvPrefix
+	}
+
+	if o.Viper {
+		args += " --viper"
+	}
+
+	if o.Completions {
+		args += " --completions"
+	}
+
+	if o.Backend != "" && o.Backend != PflagBackendName {
+		args += " --backend " + o.Backend
+	}
+
+	return args
+}
+
+func NewGenerator(fields projscan.FieldFinder, packages projscan.PackageFinder, projects projscan.ProjectFinder, structs projscan.StructFinder, fset *token.FileSet, options Options) *Generator {
+	return &Generator{fields: fields, packages: packages, projects: projects, structs: structs, fset: fset, options: options}
 }
 
 func (g *Generator) Generate(directory string, structName string, destination string) (string, error) {
+	backend, err := BackendOf(g.options.Backend)
+	if err != nil {
+		return "", err
+	}
+
+	if g.options.Viper && backend.Name() != PflagBackendName {
+		return "", errors.Errorf("the viper functions require the %s backend", PflagBackendName)
+	}
+
+	if g.options.Completions && backend.Name() != PflagBackendName {
+		return "", errors.Errorf("the shell completions require the %s backend", PflagBackendName)
+	}
+
 	pkg, err := g.packages.FindPackageByDirectory(destination)
 	if err != nil {
 		return "", err
@@ -32,17 +85,91 @@ func (g *Generator) Generate(directory string, structName string, destination st
 		return "", err
 	}
 
+	declarations, err := g.structDeclarations(st)
+	if err != nil {
+		return "", err
+	}
+
+	if err = g.checkFlags(st, declarations); err != nil {
+		return "", err
+	}
+
 	flags, err := g.structFlags(st)
 	if err != nil {
 		return "", err
 	}
 
+	required, optional := make([]string, 0), make([]string, 0)
+	for _, declaration := range declarations {
+		if FlagTagOf(declaration.Field).Required {
+			required = append(required, declaration.Flag)
+		}
+
+		if declaration.OptionalPath() != "" {
+			optional = append(optional, declaration.Flag)
+		}
+	}
+
 	fbn := changecase.Camel(path.Join(st.Name, "flags", "builder"))
+	env := &EnvMethod{FlagsBuilderName: fbn, Struct: st, Declarations: declarations, Prefix: g.options.EnvPrefix}
+	hasEnv := backend.Registers() && len(env.Variables()) > 0
+
 	blocks := []Block{
-		&SetUpConstructor{FlagsBuilderName: fbn, Struct: st},
-		&GetConstructor{FlagsBuilderName: fbn, Struct: st},
-		&FlagsBuilderStruct{Name: fbn},
-		&SetterMethod{FlagsBuilderName: fbn, Struct: st, Flags: flags},
+		&CliFlagsConstructor{Struct: st, Flags: flags, EnvPrefix: g.options.EnvPrefix, Optional: optional},
+	}
+
+	if backend.Registers() {
+		blocks = []Block{
+			&SetUpConstructor{FlagsBuilderName: fbn, Struct: st, Backend: backend},
+			&SetUpWithDefaultsConstructor{FlagsBuilderName: fbn, Struct: st, Backend: backend},
+		}
+	}
+
+	blocks = append(blocks,
+		&GetConstructor{FlagsBuilderName: fbn, Struct: st, Backend: backend, Required: len(required) > 0, Env: hasEnv},
+		&ApplyConstructor{FlagsBuilderName: fbn, Struct: st, Backend: backend, Env: hasEnv},
+		&ChangedFieldsConstructor{FlagsBuilderName: fbn, Struct: st, Backend: backend, Declarations: declarations},
+		&ChangedFieldsConstructor{FlagsBuilderName: fbn, Struct: st, Backend: backend, Declarations: declarations, JSON: true},
+	)
+
+	if g.options.Viper {
+		blocks = append(blocks,
+			&BindViperConstructor{Struct: st, Declarations: declarations},
+			&GetFromViperConstructor{Struct: st, Declarations: declarations},
+		)
+	}
+
+	// shell completions are registered to cobra commands, so they are only generated on demand to spare the import
+	if completion := (&CompletionConstructor{Struct: st, Flags: flags}); g.options.Completions && len(completion.EnumFlags()) > 0 {
+		blocks = append(blocks, completion)
+	}
+
+	blocks = append(blocks, &FlagsBuilderStruct{Name: fbn, Struct: st, Backend: backend})
+	blocks = append(blocks, backend.Blocks(st, flags)...)
+
+	if usesTextValues(flags) {
+		blocks = append(blocks, &TextValueStruct{Name: TextValueName(st)})
+	}
+
+	if backend.Registers() {
+		blocks = append(blocks,
+			&SetterMethod{FlagsBuilderName: fbn, Struct: st, Flags: flags, Backend: backend, EnvPrefix: g.options.EnvPrefix, Optional: optional},
+			&DefaultsSetterMethod{FlagsBuilderName: fbn, Struct: st, Declarations: declarations, Backend: backend, EnvPrefix: g.options.EnvPrefix},
+		)
+	}
+
+	blocks = append(blocks, &ApplyMethod{FlagsBuilderName: fbn, Struct: st, Declarations: declarations})
+
+	if len(required) > 0 {
+		blocks = append(blocks, &RequiredFlagsMethod{FlagsBuilderName: fbn, Struct: st, Declarations: declarations})
+	}
+
+	if hasEnv {
+		blocks = append(blocks, env)
+	}
+
+	if usesKeyValuePairs(declarations) {
+		blocks = append(blocks, &KeyValuePairsMethod{FlagsBuilderName: fbn})
 	}
 
 	refs, err := g.structReferences(st)
@@ -58,19 +185,24 @@ func (g *Generator) Generate(directory string, structName string, destination st
 	getterMethods := []MethodBlock{&GetterMethod{
 		FlagsBuilderName: fbn,
 		Prefix:           "",
+		FlagPrefix:       "",
 		Struct:           st,
 		Pointer:          true,
 		Fields:           fields,
+		Declarations:     declarations,
 	}}
 
 	for pair := refs.Oldest(); pair != nil; pair = pair.Next() {
-		prefix, field := pair.Key, pair.Value
+		prefix, field, flagPrefix := pair.Key, pair.Value.Field, pair.Value.FlagPrefix
 		switch KindOf(field) {
-		case FieldKindTCloudTag:
+		case FieldKindKeyValue:
+			keyValue, _ := KeyValueOf(field)
 			getterMethods = append(getterMethods, &TagsGetterMethod{
 				FlagsBuilderName: fbn,
 				Prefix:           prefix,
+				FlagPrefix:       flagPrefix,
 				Struct:           field.StructRef,
+				KeyValue:         keyValue,
 				Pointer:          field.Pointer,
 				ArrayPointer:     field.ArrayPointer,
 			})
@@ -78,8 +210,38 @@ func (g *Generator) Generate(directory string, structName string, destination st
 			getterMethods = append(getterMethods, &MapGetterMethod{
 				FlagsBuilderName: fbn,
 				Prefix:           prefix,
+				FlagPrefix:       flagPrefix,
+				Field:            field,
 				Pointer:          field.Pointer,
 			})
+		case FieldKindStructSlice:
+			itemFields, err := g.fields.FindFieldsByStruct(field.StructRef)
+			if err != nil {
+				return "", err
+			}
+
+			getterMethods = append(getterMethods, &StructSliceGetterMethod{
+				FlagsBuilderName: fbn,
+				Prefix:           prefix,
+				FlagPrefix:       flagPrefix,
+				Field:            field,
+				Fields:           itemFields,
+			})
+		case FieldKindTime:
+			getterMethods = append(getterMethods, &TimeGetterMethod{
+				FlagsBuilderName: fbn,
+				Prefix:           prefix,
+				FlagPrefix:       flagPrefix,
+				Field:            field,
+			})
+		case FieldKindValue:
+			getterMethods = append(getterMethods, &ValueGetterMethod{
+				FlagsBuilderName: fbn,
+				TextValueName:    TextValueName(st),
+				Prefix:           prefix,
+				FlagPrefix:       flagPrefix,
+				Field:            field,
+			})
 		case FieldKindStruct:
 			subFields, err := g.fields.FindFieldsByStruct(field.StructRef)
 			if err != nil {
@@ -89,9 +251,11 @@ func (g *Generator) Generate(directory string, structName string, destination st
 			getterMethods = append(getterMethods, &GetterMethod{
 				FlagsBuilderName: fbn,
 				Prefix:           prefix,
+				FlagPrefix:       flagPrefix,
 				Struct:           field.StructRef,
 				Pointer:          field.Pointer,
 				Fields:           subFields,
+				Declarations:     declarations,
 			})
 		default:
 			fmt.Println("")
@@ -106,6 +270,7 @@ func (g *Generator) Generate(directory string, structName string, destination st
 		Struct:  st,
 		Package: pkg,
 		Blocks:  blocks,
+		Options: g.options,
 	}
 
 	imports, err := g.structImports(st)
@@ -129,3 +294,27 @@ func (g *Generator) Generate(directory string, structName string, destination st
 
 	return filepath, nil
 }
+
+// usesTextValues returns true if any of the flags is registered with the text value adapter.
+func usesTextValues(flags *orderedmap.OrderedMap[string, []*projscan.Field]) bool {
+	for pair := flags.Oldest(); pair != nil; pair = pair.Next() {
+		for _, field := range pair.Value {
+			if KindOf(field) == FieldKindValue && !field.TypeRef.PflagValue {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// usesKeyValuePairs returns true if any of the flags holds key-value pairs, read by the key-value pairs method.
+func usesKeyValuePairs(declarations []*FlagDeclaration) bool {
+	for _, declaration := range declarations {
+		if kind := KindOf(declaration.Field); kind == FieldKindStringMap || kind == FieldKindKeyValue || kind == FieldKindStructSlice {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,763 @@
+package code
+
+import (
+	"bytes"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/totvs-cloud/pflagstruct/internal/scan/fld"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/pkg"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/proj"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/st"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/typ"
+	"github.com/totvs-cloud/pflagstruct/internal/syntree"
+	"golang.org/x/exp/slog"
+)
+
+// pflagMain is the program reading the struct from pflag flags with the getter and printing it as JSON.
+const pflagMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	SetUp{{.}}ToFlags(flags)
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	value, err := Get{{.}}FromFlags(flags)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	encoded, _ := json.Marshal(value)
+	fmt.Println(string(encoded))
+}
+`
+
+// usageMain is the program printing the usage message of the pflag flags.
+const usageMain = `package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	SetUp{{.}}ToFlags(flags)
+	fmt.Print(flags.FlagUsages())
+}
+`
+
+// completionsMain is the program running a cobra command with the shell completions of the struct registered, which
+// reads the struct from its flags and prints it as JSON.
+const completionsMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	cmd := &cobra.Command{
+		Use:           "test",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := Get{{.}}FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			encoded, _ := json.Marshal(value)
+			fmt.Println(string(encoded))
+			return nil
+		},
+	}
+	SetUp{{.}}ToFlags(cmd.Flags())
+	if err := Register{{.}}FlagCompletions(cmd); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	cmd.SetArgs(os.Args[1:])
+	if err := cmd.Execute(); err != nil {
+		fmt.Println("error:", err)
+	}
+}
+`
+
+// defaultsMain is the program registering the pflag flags with the defaults returned by the Default function of the
+// struct, or with none when NO_DEFAULTS is set, and printing the struct read with the getter as JSON.
+const defaultsMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/totvs-cloud/pflagstruct/internal/code/testdata/model"
+)
+
+func main() {
+	defaults := model.Default{{.}}()
+	if os.Getenv("NO_DEFAULTS") != "" {
+		defaults = nil
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	SetUp{{.}}ToFlagsWithDefaults(flags, defaults)
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	value, err := Get{{.}}FromFlags(flags)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	encoded, _ := json.Marshal(value)
+	fmt.Println(string(encoded))
+}
+`
+
+// changedMain is the program printing the Go and JSON paths of the fields whose pflag flags were changed.
+const changedMain = `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	SetUp{{.}}ToFlags(flags)
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	fmt.Println(strings.Join(Changed{{.}}FieldsFromFlags(flags), ","))
+	fmt.Println(strings.Join(Changed{{.}}JSONFieldsFromFlags(flags), ","))
+}
+`
+
+// applyMain is the program applying the pflag flags to the struct decoded from the JSON held by TARGET, if any, and
+// printing it as JSON.
+const applyMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/totvs-cloud/pflagstruct/internal/code/testdata/model"
+)
+
+func main() {
+	target := new(model.{{.}})
+	if encoded, ok := os.LookupEnv("TARGET"); ok {
+		if err := json.Unmarshal([]byte(encoded), target); err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	SetUp{{.}}ToFlags(flags)
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	if err := Apply{{.}}Flags(flags, target); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	encoded, _ := json.Marshal(target)
+	fmt.Println(string(encoded))
+}
+`
+
+// cobraMain is the program running a cobra command that reads the struct from its flags and prints it as JSON.
+const cobraMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	cmd := &cobra.Command{
+		Use:           "test",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := Get{{.}}FromFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			encoded, _ := json.Marshal(value)
+			fmt.Println(string(encoded))
+			return nil
+		},
+	}
+	SetUp{{.}}ToFlags(cmd.Flags())
+
+	cmd.SetArgs(os.Args[1:])
+	if err := cmd.Execute(); err != nil {
+		fmt.Println("error:", err)
+	}
+}
+`
+
+// viperMain is the program binding the pflag flags to viper, along with the YAML configuration in the CONFIG
+// environment variable, and printing the struct read from viper as JSON.
+const viperMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func main() {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	SetUp{{.}}ToFlags(flags)
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(os.Getenv("CONFIG"))); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	if err := Bind{{.}}FlagsToViper(v, flags); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	value, err := Get{{.}}FromViper(v)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	encoded, _ := json.Marshal(value)
+	fmt.Println(string(encoded))
+}
+`
+
+// flagMain is the program reading the struct from the flags of the standard library, as pflagMain does.
+const flagMain = `package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	SetUp{{.}}ToFlags(flags)
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	value, err := Get{{.}}FromFlags(flags)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	encoded, _ := json.Marshal(value)
+	fmt.Println(string(encoded))
+}
+`
+
+// cliMain is the program reading the struct from the flags of a urfave/cli application and printing it as JSON, or
+// applying them to the struct decoded from the JSON held by TARGET when it is set, as applyMain does.
+const cliMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/totvs-cloud/pflagstruct/internal/code/testdata/model"
+)
+
+func main() {
+	app := &cli.App{
+		Name:      "test",
+		Flags:     {{.}}Flags(),
+		Writer:    io.Discard,
+		ErrWriter: io.Discard,
+		Action: func(c *cli.Context) error {
+			var value interface{}
+			var err error
+			if encoded, ok := os.LookupEnv("TARGET"); ok {
+				target := new(model.{{.}})
+				if err := json.Unmarshal([]byte(encoded), target); err != nil {
+					return err
+				}
+
+				value, err = target, Apply{{.}}FlagsFromContext(c, target)
+			} else {
+				value, err = Get{{.}}FromContext(c)
+			}
+			if err != nil {
+				return err
+			}
+
+			encoded, _ := json.Marshal(value)
+			fmt.Println(string(encoded))
+			return nil
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Println("error:", err)
+	}
+}
+`
+
+func TestGenerator_Generate(t *testing.T) {
+	t.Run("unsigned slices", func(t *testing.T) {
+		program := newFixture(t).program(t, "Sizes", Options{}, pflagMain)
+
+		// encoding/json writes []uint8 in base64, so [0,255] is AP8=
+		require.Equal(t, `{"Small":"AP8=","Medium":[65535],"Large":[4294967295]}`, program.run(t, nil, "--small", "0,255", "--medium", "65535", "--large", "4294967295"))
+		require.Equal(t, `error: error retrieving "small" from command flags: value 256 out of range for uint8`, program.run(t, nil, "--small", "255,256"))
+		require.Equal(t, `error: error retrieving "medium" from command flags: value 65536 out of range for uint16`, program.run(t, nil, "--medium", "65536"))
+		require.Equal(t, `error: error retrieving "large" from command flags: value 4294967296 out of range for uint32`, program.run(t, nil, "--large", "4294967296"))
+	})
+
+	t.Run("text values", func(t *testing.T) {
+		fixture := newFixture(t)
+		program := fixture.program(t, "Release", Options{}, pflagMain)
+
+		require.Equal(t, `{"name":"app","version":"v1.2"}`, program.run(t, nil, "--name", "app", "--version", "v1.2"))
+		require.Equal(t, `{"name":"app"}`, program.run(t, nil, "--name", "app"))
+
+		// the zero value of a text value is no default, so it is left out of the usage message
+		usage := fixture.program(t, "Release", Options{}, usageMain)
+		require.NotContains(t, usage.run(t, nil), "(default")
+	})
+
+	t.Run("completions", func(t *testing.T) {
+		fixture := newFixture(t)
+
+		// cobra is only imported when the completions are asked for
+		dir, err := fixture.generate(t, "Plan", Options{}, pflagMain)
+		require.NoError(t, err)
+		code := generatedCode(t, dir)
+		require.Contains(t, code, "func GetPlanFromFlags(")
+		require.NotContains(t, code, "github.com/spf13/cobra")
+
+		program := fixture.program(t, "Plan", Options{Completions: true}, completionsMain)
+		require.Equal(t, `{"name":"basic","tier":"free"}`, program.run(t, nil, "--name", "basic", "--tier", "free"))
+		require.Equal(t, "free\nstandard\nenterprise\n:4\nCompletion ended with directive: ShellCompDirectiveNoFileComp", program.run(t, nil, "__complete", "--tier", ""))
+
+		_, err = fixture.generate(t, "Plan", Options{Completions: true, Backend: FlagBackendName}, pflagMain)
+		require.EqualError(t, err, "the shell completions require the pflag backend")
+	})
+
+	t.Run("short prefixes", func(t *testing.T) {
+		fixture := newFixture(t)
+
+		program := fixture.program(t, "Cluster", Options{}, pflagMain)
+		require.Equal(t, `{"database":{"host":"db","credentials":{"user":"admin"}}}`, program.run(t, nil, "--database-host", "db", "--database-credentials-user", "admin"))
+
+		// nested fields are prefixed with the name of the innermost struct field only
+		program = fixture.program(t, "Cluster", Options{ShortPrefixes: true}, pflagMain)
+		require.Equal(t, `{"database":{"host":"db","credentials":{"user":"admin"}}}`, program.run(t, nil, "--database-host", "db", "--credentials-user", "admin"))
+		require.Equal(t, `error: unknown flag: --database-credentials-user`, program.run(t, nil, "--database-credentials-user", "admin"))
+	})
+
+	t.Run("default values", func(t *testing.T) {
+		program := newFixture(t).program(t, "Defaults", Options{}, pflagMain)
+
+		require.Equal(t, `{"Port":127,"Ports":[80,443],"Timeout":90000000000,"Backoffs":[1000000,7200000000000],"Since":"2024-01-02T00:00:00Z","Level":"info","Labels":{"a":1,"b":2}}`, program.run(t, nil))
+		require.Equal(t, `{"Port":1,"Ports":[8080],"Timeout":90000000000,"Backoffs":[1000000,7200000000000],"Since":"2024-01-02T00:00:00Z","Level":"debug","Labels":{"a":1,"b":2}}`, program.run(t, nil, "--port", "1", "--ports", "8080", "--level", "debug"))
+	})
+
+	t.Run("defaults from a struct", func(t *testing.T) {
+		program := newFixture(t).program(t, "Config", Options{}, defaultsMain)
+
+		require.Equal(t, `{"name":"service","port":9090,"timeout":60000000000,"labels":{"env":"dev"},"database":{"host":"localhost","credentials":{"user":"admin"}}}`, program.run(t, nil))
+		require.Equal(t, `{"name":"flag","port":9090,"timeout":60000000000,"labels":{"env":"dev"},"database":{"host":"db","credentials":{"user":"admin"}}}`, program.run(t, nil, "--name", "flag", "--database-host", "db"))
+		// the default struct tags are only used without a struct
+		require.Equal(t, `{"name":"app","port":8080,"timeout":0,"labels":null,"database":{"host":"","credentials":{"user":""}}}`, program.run(t, []string{"NO_DEFAULTS=1"}))
+	})
+
+	t.Run("required flags", func(t *testing.T) {
+		program := newFixture(t).program(t, "Client", Options{}, pflagMain)
+
+		require.Equal(t, `error: required flag(s) "name" not set`, program.run(t, nil))
+		// the proxy is optional, so its URL is only required when one of its flags is set
+		require.Equal(t, `{"Name":"client","Proxy":null}`, program.run(t, nil, "--name", "client"))
+		require.Equal(t, `error: required flag(s) "name", "proxy-url" not set`, program.run(t, nil, "--proxy-timeout", "1s"))
+		require.Equal(t, `{"Name":"client","Proxy":{"URL":"http://proxy","Timeout":0}}`, program.run(t, nil, "--name", "client", "--proxy-url", "http://proxy"))
+	})
+
+	t.Run("hidden and deprecated flags", func(t *testing.T) {
+		fixture := newFixture(t)
+
+		// hidden and deprecated flags are left out of the usage message, as are deprecated shorthands
+		usage := fixture.program(t, "User", Options{}, usageMain)
+		require.Equal(t, "-n, --name string   \n      --verbose", usage.run(t, nil))
+
+		// deprecated flags and shorthands are still read, warning about their use
+		program := fixture.program(t, "User", Options{}, pflagMain)
+		require.Equal(t, `Flag --full-name has been deprecated, use --name instead
+Flag shorthand -v has been deprecated, use --verbose instead
+{"name":"","fullName":"John Doe","verbose":true,"token":"secret"}`, program.run(t, nil, "-f", "John Doe", "-v", "--token", "secret"))
+	})
+
+	t.Run("pointer fields", func(t *testing.T) {
+		program := newFixture(t).program(t, "Quota", Options{}, pflagMain)
+
+		// the nested pointer is left nil unless one of its flags is set, those of maps and key-value pairs included
+		require.Equal(t, `{"name":"","limits":null}`, program.run(t, nil))
+		require.Equal(t, `{"name":"","limits":{"metadata":{"a":"b"},"window":{"seconds":0}}}`, program.run(t, nil, "--limits-metadata", "a=b"))
+		require.Equal(t, `{"name":"","limits":{"labels":[{"Key":"env","Value":"dev"}],"window":{"seconds":0}}}`, program.run(t, nil, "--limits-labels", "env=dev"))
+		require.Equal(t, `{"name":"","limits":{"tags":[{"Key":"env","Value":"dev"}],"window":{"seconds":0}}}`, program.run(t, nil, "--limits-tags", "env=dev"))
+		require.Equal(t, `{"name":"","limits":{"notes":[{"Key":"env","Value":"dev"}],"window":{"seconds":0}}}`, program.run(t, nil, "--limits-notes", "env=dev"))
+		require.Equal(t, `{"name":"","limits":{"window":{"seconds":0}}}`, program.run(t, nil, "--limits-window-seconds", "0"))
+		// pointer fields explicitly set to the zero value are assigned
+		require.Equal(t, `{"name":"","limits":{"max":0,"enabled":false,"window":{"seconds":0}}}`, program.run(t, nil, "--limits-max", "0", "--limits-enabled=false"))
+	})
+
+	t.Run("changed fields", func(t *testing.T) {
+		program := newFixture(t).program(t, "Quota", Options{}, changedMain)
+
+		require.Equal(t, ``, program.run(t, nil))
+		// the fields are named by their Go path first, and by their JSON path then
+		require.Equal(t, `Name,Limits.Max,Limits.Window.Seconds
+name,limits.max,limits.window.seconds`, program.run(t, nil, "--name", "basic", "--limits-max", "0", "--limits-window-seconds", "30"))
+		require.Equal(t, `Limits.Metadata,Limits.Labels
+limits.metadata,limits.labels`, program.run(t, nil, "--limits-metadata", "a=b", "--limits-labels", "env=dev"))
+	})
+
+	t.Run("apply flags", func(t *testing.T) {
+		fixture := newFixture(t)
+
+		// only the fields whose flags were changed are assigned, allocating nested pointers when needed
+		program := fixture.program(t, "Quota", Options{}, applyMain)
+		target := []string{`TARGET={"name":"basic"}`}
+		require.Equal(t, `{"name":"basic","limits":null}`, program.run(t, target))
+		require.Equal(t, `{"name":"basic","limits":{"max":0,"window":{"seconds":0}}}`, program.run(t, target, "--limits-max", "0"))
+		require.Equal(t, `{"name":"basic","limits":{"max":5,"enabled":true,"window":{"seconds":30}}}`, program.run(t, []string{`TARGET={"name":"basic","limits":{"max":5,"window":{"seconds":30}}}`}, "--limits-enabled"))
+
+		program = fixture.program(t, "Sizes", Options{}, applyMain)
+		require.Equal(t, `{"Small":"AP8=","Medium":[1]}`, program.run(t, []string{`TARGET={"Medium":[1]}`}, "--small", "0,255"))
+		require.Equal(t, `error: error retrieving "large" from command flags: value 4294967296 out of range for uint32`, program.run(t, nil, "--large", "4294967296"))
+
+		// flags left unset are not applied, so their defaults do not overwrite the target
+		program = fixture.program(t, "Defaults", Options{}, applyMain)
+		require.Equal(t, `{"Port":5,"Ports":null,"Timeout":0,"Backoffs":null,"Since":"0001-01-01T00:00:00Z","Level":"","Labels":null}`, program.run(t, []string{`TARGET={"Port":5}`}))
+
+		// the target may already hold the required fields, so they are not checked
+		program = fixture.program(t, "Client", Options{}, applyMain)
+		require.Equal(t, `{"Name":"","Proxy":{"URL":"","Timeout":1000000000}}`, program.run(t, nil, "--proxy-timeout", "1s"))
+	})
+
+	t.Run("environment variables", func(t *testing.T) {
+		fixture := newFixture(t)
+		env := []string{"APP_HOST=env", "APP_PORT=80", "SERVICE_TOKEN=secret", "APP_DEBUG=true"}
+
+		for _, main := range []string{pflagMain, applyMain} {
+			program := fixture.program(t, "Service", Options{EnvPrefix: "APP"}, main)
+
+			// the env struct tag replaces the variable given by the prefix, and env:"-" disables it
+			require.Equal(t, `{"Host":"env","Port":80,"Token":"secret","Debug":false}`, program.run(t, env))
+			require.Equal(t, `{"Host":"flag","Port":80,"Token":"secret","Debug":false}`, program.run(t, env, "--host", "flag"))
+			// variables of flags set on the command line are not read
+			require.Equal(t, `{"Host":"","Port":8080,"Token":"","Debug":false}`, program.run(t, []string{"APP_PORT=invalid"}, "--port", "8080"))
+			require.Equal(t, `error: error retrieving "port" from environment variable APP_PORT: invalid argument "invalid" for "--port" flag: strconv.ParseUint: parsing "invalid": invalid syntax`, program.run(t, []string{"APP_PORT=invalid"}))
+		}
+
+		// cobra does not require the flags read from environment variables, which are checked by the getter instead
+		program := fixture.program(t, "Account", Options{EnvPrefix: "APP"}, cobraMain)
+		require.Equal(t, `{"Owner":"env"}`, program.run(t, []string{"APP_OWNER=env"}))
+		require.Equal(t, `{"Owner":"flag"}`, program.run(t, nil, "--owner", "flag"))
+		require.Equal(t, `error: required flag(s) "owner" not set`, program.run(t, nil))
+	})
+
+	t.Run("viper", func(t *testing.T) {
+		program := newFixture(t).program(t, "Settings", Options{Viper: true}, viperMain)
+		config := "CONFIG=name: config\ntimeout: 1m\nlabels:\n  team: core\nupstream:\n  url: http://proxy\nsecret: ignored\n"
+
+		require.Equal(t, `{"Name":"config","Timeout":60000000000,"Labels":{"team":"core"},"Proxy":{"URL":"http://proxy","Timeout":0},"Secret":""}`, program.run(t, []string{config}))
+		// bound flags set on the command line take precedence over the configuration
+		require.Equal(t, `{"Name":"flag","Timeout":60000000000,"Labels":{"team":"core"},"Proxy":{"URL":"http://proxy","Timeout":0},"Secret":""}`, program.run(t, []string{config}, "--name", "flag"))
+		// values are parsed as they are on the command line
+		require.Equal(t, `error: error retrieving "timeout" from viper key "timeout": invalid argument "soon" for "--timeout" flag: time: invalid duration "soon"`, program.run(t, []string{"CONFIG=timeout: soon\n"}))
+	})
+
+	t.Run("flag backend", func(t *testing.T) {
+		program := newFixture(t).program(t, "Server", Options{Backend: FlagBackendName}, flagMain)
+
+		// shorthands are flags of their own, and repeating a slice flag appends to it
+		require.Equal(t, `{"Name":"api","Port":8080,"Hosts":["a","b","c"],"Weights":[1,2],"Timeout":1000000000,"Since":"2024-01-02T00:00:00Z","Level":"debug","Labels":{"team":"core"},"Proxy":{"URL":"http://proxy","Timeout":0}}`, program.run(t, nil, "-n", "api", "-hosts", "a,b", "-hosts", "c", "-weights", "1,2", "-timeout", "1s", "-since", "2024-01-02", "-level", "debug", "-labels", "team=core", "-proxy-url", "http://proxy"))
+		require.Equal(t, `error: invalid value "65536" for flag -port: strconv.ParseUint: parsing "65536": value out of range`, program.run(t, nil, "-port", "65536"))
+		require.Equal(t, `error: required flag(s) "proxy-url" not set`, program.run(t, nil, "-proxy-timeout", "1s"))
+	})
+
+	t.Run("cli backend", func(t *testing.T) {
+		program := newFixture(t).program(t, "Server", Options{Backend: CliBackendName}, cliMain)
+
+		require.Equal(t, `{"Name":"api","Port":8080,"Hosts":["a","b"],"Weights":[1,2],"Timeout":1000000000,"Since":"2024-01-02T00:00:00Z","Level":"debug","Labels":{"team":"core"},"Proxy":{"URL":"http://proxy","Timeout":0}}`, program.run(t, nil, "-n", "api", "--hosts", "a,b", "--weights", "1,2", "--timeout", "1s", "--since", "2024-01-02", "--level", "debug", "--labels", "team=core", "--proxy-url", "http://proxy"))
+		require.Equal(t, `{"Name":"api","Port":9090,"Hosts":null,"Weights":null,"Timeout":0,"Since":"0001-01-01T00:00:00Z","Level":"","Labels":null,"Proxy":null}`, program.run(t, []string{`TARGET={"Port":9090}`}, "--name", "api"))
+		require.Equal(t, `error: error retrieving "level" from command flags: invalid value "trace", allowed values are debug, info`, program.run(t, nil, "--level", "trace"))
+		// cli does not require the flags of the proxy, which are checked once one of them is set
+		require.Equal(t, `error: required flag(s) "proxy-url" not set`, program.run(t, nil, "--proxy-timeout", "1s"))
+	})
+
+	t.Run("slices of structs", func(t *testing.T) {
+		program := newFixture(t).program(t, "Gateway", Options{}, pflagMain)
+
+		require.Equal(t, `{"Upstreams":[{"Host":"a","Port":80,"Timeout":1000000000,"Query":""},{"Host":"b","Port":0,"Timeout":0,"Query":"x=y"}],"Meta":{"query":"x=y"}}`, program.run(t, nil, "--upstreams", "host=a, port=80,timeout=1s", "--upstreams", "host=b,query=x=y", "--meta", "query=x=y"))
+		require.Equal(t, `{"Upstreams":[{"Host":"c","Port":0,"Timeout":0,"Query":"a,b"},{"Host":"","Port":0,"Timeout":0,"Query":"c,d"},{"Host":"json","Port":0,"Timeout":0,"Query":""}],"Meta":null}`, program.run(t, nil, "--upstreams", `"query=a,b",host=c`, "--upstreams", `query=c\,d`, "--upstreams", `{"Host":"json"}`))
+		require.Equal(t, `error: error parsing "upstreams" from command flags: item 2 (host): "host" is not a key-value pair such as key=value`, program.run(t, nil, "--upstreams", "host=a", "--upstreams", "host"))
+		require.Equal(t, `error: error parsing "upstreams" from command flags: item 1 (port=x): invalid value of port: strconv.ParseUint: parsing "x": invalid syntax`, program.run(t, nil, "--upstreams", "port=x"))
+	})
+
+	t.Run("key-value tag", func(t *testing.T) {
+		program := newFixture(t).program(t, "Resource", Options{}, pflagMain)
+
+		require.Equal(t, `{"labels":[{"Key":"env","Value":"dev"},{"Key":"team","Value":"core"}],"metadata":[{"Name":"owner","Data":"ops"}]}`, program.run(t, nil, "--labels", "env=dev,team=core", "--metadata", "owner=ops"))
+		require.Equal(t, `{"labels":null,"metadata":null}`, program.run(t, nil))
+		require.Equal(t, `error: error parsing "labels" from command flags: "env" is not a key-value pair such as key=value`, program.run(t, nil, "--labels", "env"))
+	})
+
+	t.Run("maps", func(t *testing.T) {
+		fixture := newFixture(t)
+
+		usage := fixture.program(t, "Catalog", Options{}, usageMain)
+		require.Equal(t, `--enabled strings    provide the desired key-value pairs separated by commas, with values of type bool (enabled key1=bool,key2=bool)
+      --headers strings    provide the desired key-value pairs separated by commas, repeating a key to give it several values (headers key1=value1,key1=value2,key2=value3)
+      --limits strings     provide the desired key-value pairs separated by commas, with values of type int64 (limits key1=int64,key2=int64)
+      --owners strings     provide the desired key-value pairs separated by commas (owners key1=value1,key2=value2,key3=value3)
+      --prices strings     provide the desired key-value pairs separated by commas, with values of type float64 (prices key1=float64,key2=float64), the keys being one of: free, standard, enterprise (default [free=0,standard=9.5])
+      --tiers strings      provide the desired key-value pairs separated by commas, with values of type tier (tiers key1=tier,key2=tier), the values being one of: free, standard, enterprise
+      --timeouts strings   provide the desired key-value pairs separated by commas, with values of type duration (timeouts key1=duration,key2=duration)`, usage.run(t, nil))
+
+		// maps keyed by types other than strings are left out, warning about them
+		var logs bytes.Buffer
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+		program := fixture.program(t, "Catalog", Options{}, pflagMain)
+		require.Contains(t, logs.String(), `msg="map field type not supported" Field=Catalog.Zones FieldType=map[int]string`)
+
+		require.Equal(t, `{"enabled":{"api":true,"ui":false},"limits":{"cpu":2,"memory":512},"headers":{"Accept":["text/html","application/json"]},"timeouts":{"read":1000000000},"tiers":{"alice":"free"},"prices":{"enterprise":99.5},"owners":{"eu":"ops"}}`, program.run(t, nil, "--enabled", "api=true,ui=false", "--limits", "cpu=2,memory=512", "--headers", "Accept=text/html,Accept=application/json", "--timeouts", "read=1s", "--tiers", "alice=free", "--prices", "enterprise=99.5", "--owners", "eu=ops"))
+		require.Equal(t, `{"prices":{"free":0,"standard":9.5}}`, program.run(t, nil))
+		require.Equal(t, `error: error parsing "limits" from command flags: invalid value of key "cpu": strconv.ParseInt: parsing "x": invalid syntax`, program.run(t, nil, "--limits", "cpu=x"))
+		require.Equal(t, `error: error parsing "tiers" from command flags: invalid value "gold" of key "alice", allowed values are free, standard, enterprise`, program.run(t, nil, "--tiers", "alice=gold"))
+		require.Equal(t, `error: error parsing "prices" from command flags: invalid key "gold", allowed keys are free, standard, enterprise`, program.run(t, nil, "--prices", "gold=1"))
+
+		// the maps of a struct given as defaults are read back as they were
+		defaults := fixture.program(t, "Catalog", Options{}, defaultsMain)
+		require.Equal(t, `{"enabled":{"api":true,"ui":false},"limits":{"cpu":2,"memory":512},"headers":{"Accept":["text/html","application/json"]},"timeouts":{"read":1000000000},"tiers":{"alice":"enterprise"},"prices":{"standard":9.5},"owners":{"eu":"ops"}}`, defaults.run(t, nil))
+	})
+
+	t.Run("key-value pairs", func(t *testing.T) {
+		fixture := newFixture(t)
+		pflagProgram := fixture.program(t, "Gateway", Options{}, pflagMain)
+		flagProgram := fixture.program(t, "Gateway", Options{Backend: FlagBackendName}, flagMain)
+		cliProgram := fixture.program(t, "Gateway", Options{Backend: CliBackendName}, cliMain)
+
+		for _, program := range []*program{pflagProgram, flagProgram, cliProgram} {
+			require.Equal(t, `{"Upstreams":null,"Meta":{"a":"b,c","q":"x=y","z":"1"}}`, program.run(t, nil, "--meta", `q=x=y,a=b\,c,z=1`))
+			require.Equal(t, `{"Upstreams":null,"Meta":{"a":"b,c","z":"1"}}`, program.run(t, nil, "--meta", `"a=b,c",z=1`))
+			require.Equal(t, `{"Upstreams":[{"Host":"c","Port":0,"Timeout":0,"Query":"a,b"}],"Meta":null}`, program.run(t, nil, "--upstreams", `query="a,b",host=c`))
+			require.Equal(t, `error: error parsing "upstreams" from command flags: item 1 (query="a,b): "query=\"a,b" has an unterminated quote`, program.run(t, nil, "--upstreams", `query="a,b`))
+		}
+
+		// pflag and the flag adapter read the values of maps as CSV, which only allows quoting whole pairs
+		require.Equal(t, `error: invalid argument "a=\"b,c\"" for "--meta" flag: parse error on line 1, column 3: bare " in non-quoted-field`, pflagProgram.run(t, nil, "--meta", `a="b,c"`))
+		require.Equal(t, `error: invalid value "a=\"b,c\"" for flag -meta: parse error on line 1, column 3: bare " in non-quoted-field`, flagProgram.run(t, nil, "--meta", `a="b,c"`))
+		require.Equal(t, `{"Upstreams":null,"Meta":{"a":"b,c","z":"1"}}`, cliProgram.run(t, nil, "--meta", `a="b,c",z=1`))
+		require.Equal(t, `error: error parsing "meta" from command flags: "a=\"b" has an unterminated quote`, cliProgram.run(t, nil, "--meta", `a="b`))
+	})
+}
+
+// fixture is a copy of the module in testdata, where the code generated for its structs is built and run.
+type fixture struct {
+	dir string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("building the generated code is skipped in short mode")
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("building the generated code requires the go command")
+	}
+
+	if _, ok := os.LookupEnv("GOROOT"); !ok {
+		t.Setenv("GOROOT", runtime.GOROOT())
+	}
+
+	dir := t.TempDir()
+	err := filepath.WalkDir("testdata", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel("testdata", path)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0o755)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(dir, rel), data, 0o644)
+	})
+	require.NoError(t, err)
+
+	return &fixture{dir: dir}
+}
+
+// generate writes the given main package, where {{.}} stands for the struct name, to a new directory of the fixture
+// and generates the code of the struct of the model package in it, returning the directory.
+func (f *fixture) generate(t *testing.T, structName string, options Options, main string) (string, error) {
+	t.Helper()
+
+	dir, err := os.MkdirTemp(f.dir, strings.ToLower(structName))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(strings.ReplaceAll(main, "{{.}}", structName)), 0o644))
+
+	_, err = newGenerator(options).Generate(filepath.Join(f.dir, "model"), structName, dir)
+
+	return dir, err
+}
+
+// program generates the code of the struct with the given main package and builds it.
+func (f *fixture) program(t *testing.T, structName string, options Options, main string) *program {
+	t.Helper()
+
+	dir, err := f.generate(t, structName, options, main)
+	require.NoError(t, err)
+
+	binary := filepath.Join(dir, "program")
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	return &program{binary: binary}
+}
+
+// generatedCode returns the code generated to the directory, leaving out the main file of the program.
+func generatedCode(t *testing.T, dir string) string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	require.NoError(t, err)
+
+	var code string
+	for _, file := range files {
+		if filepath.Base(file) == "main.go" {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		code += string(content)
+	}
+
+	return code
+}
+
+// program is a built program using the generated code.
+type program struct {
+	binary string
+}
+
+// run runs the program with the given environment variables and arguments, returning what it printed.
+func (p *program) run(t *testing.T, env []string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command(p.binary, args...)
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	return strings.TrimSpace(string(output))
+}
+
+func newGenerator(options Options) *Generator {
+	scanner := syntree.NewScanner(token.NewFileSet())
+	projects := proj.NewFinder(scanner)
+	packages := pkg.NewFinder(scanner, projects)
+	structs := st.NewFinder(scanner, projects, packages)
+	types := typ.NewFinder(scanner, packages)
+	fields := fld.NewFinder(packages, projects, structs, types)
+
+	return NewGenerator(fields, packages, projects, structs, scanner.FileSet(), options)
+}
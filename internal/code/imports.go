@@ -14,15 +14,28 @@ func (g *Generator) structImports(st *projscan.Struct) ([]*projscan.Package, err
 		return nil, err
 	}
 
+	flags, err := g.structFlags(st)
+	if err != nil {
+		return nil, err
+	}
+
 	pkgsmap := map[string]*projscan.Package{st.Package.Path: st.Package}
 
 	for pair := refs.Oldest(); pair != nil; pair = pair.Next() {
-		ref := pair.Value
+		ref := pair.Value.Field
 		if !ref.FromStandardLibrary() {
 			pkgsmap[ref.StructRef.Package.Path] = ref.StructRef.Package
 		}
 	}
 
+	for pair := flags.Oldest(); pair != nil; pair = pair.Next() {
+		for _, fld := range pair.Value {
+			if fld.TypeRef != nil && !fld.TypeRef.Package.FromStandardLibrary() {
+				pkgsmap[fld.TypeRef.Package.Path] = fld.TypeRef.Package
+			}
+		}
+	}
+
 	pkgs := make([]*projscan.Package, 0)
 	for _, pkg := range pkgsmap {
 		pkgs = append(pkgs, pkg)
@@ -31,23 +44,39 @@ func (g *Generator) structImports(st *projscan.Struct) ([]*projscan.Package, err
 	return pkgs, nil
 }
 
-func (g *Generator) structReferences(st *projscan.Struct) (*orderedmap.OrderedMap[string, *projscan.Field], error) {
+// Reference is a field retrieved by a dedicated getter method, named after the path of the field.
+type Reference struct {
+	Field      *projscan.Field
+	FlagPrefix string // Prefix of the flags of the struct fields, or the flag itself for any other field
+}
+
+// referenceFlagPrefix returns the flag prefix of the reference to the given field, nested in a struct whose flags are
+// prefixed by parent. Structs prefix the flags of their fields, while any other field is registered as a single flag.
+func (g *Generator) referenceFlagPrefix(parent string, field *projscan.Field) string {
+	if KindOf(field) == FieldKindStruct {
+		return g.flagPrefixOf(parent, field)
+	}
+
+	return flagName(parent, field)
+}
+
+func (g *Generator) structReferences(st *projscan.Struct) (*orderedmap.OrderedMap[string, *Reference], error) {
 	flds, err := g.fields.FindFieldsByStruct(st)
 	if err != nil {
 		return nil, err
 	}
 
-	refs := orderedmap.New[string, *projscan.Field]()
+	refs := orderedmap.New[string, *Reference]()
 
 	for _, fld := range flds {
 		switch KindOf(fld) {
-		case FieldKindStruct, FieldKindTCloudTag:
-			extracted, err := g.fieldReferences(fld, changecase.Param(fld.Name))
+		case FieldKindStruct, FieldKindKeyValue:
+			extracted, err := g.fieldReferences(fld, changecase.Param(fld.Name), g.referenceFlagPrefix("", fld))
 			if err != nil {
 				return nil, err
 			}
 
-			merged := orderedmap.New[string, *projscan.Field]()
+			merged := orderedmap.New[string, *Reference]()
 			// Copy refs to merged map
 			for pair := refs.Oldest(); pair != nil; pair = pair.Next() {
 				merged.Set(pair.Key, pair.Value)
@@ -58,17 +87,17 @@ func (g *Generator) structReferences(st *projscan.Struct) (*orderedmap.OrderedMa
 			}
 
 			refs = merged
-		case FieldKindStringMap:
-			refs.Set(changecase.Param(fld.Name), fld)
+		case FieldKindStringMap, FieldKindTime, FieldKindValue, FieldKindStructSlice:
+			refs.Set(changecase.Param(fld.Name), &Reference{Field: fld, FlagPrefix: g.referenceFlagPrefix("", fld)})
 		}
 	}
 
 	return refs, nil
 }
 
-func (g *Generator) fieldReferences(st *projscan.Field, prefix string) (*orderedmap.OrderedMap[string, *projscan.Field], error) {
-	refs := orderedmap.New[string, *projscan.Field]()
-	refs.Set(prefix, st)
+func (g *Generator) fieldReferences(st *projscan.Field, prefix, flagPrefix string) (*orderedmap.OrderedMap[string, *Reference], error) {
+	refs := orderedmap.New[string, *Reference]()
+	refs.Set(prefix, &Reference{Field: st, FlagPrefix: flagPrefix})
 
 	flds, err := g.fields.FindFieldsByStruct(st.StructRef)
 	if err != nil { // TODO: warn here
@@ -76,17 +105,19 @@ func (g *Generator) fieldReferences(st *projscan.Field, prefix string) (*ordered
 	}
 
 	for _, fld := range flds {
-		if fld.StructRef != nil {
+		switch KindOf(fld) {
+		case FieldKindStringMap, FieldKindTime, FieldKindValue, FieldKindStructSlice:
+			refs.Set(changecase.Param(path.Join(prefix, fld.Name)), &Reference{Field: fld, FlagPrefix: g.referenceFlagPrefix(flagPrefix, fld)})
+		case FieldKindStruct, FieldKindKeyValue:
 			p := changecase.Param(path.Join(prefix, fld.Name))
-			refs.Set(p, fld)
 
-			extracted, err := g.fieldReferences(fld, p)
+			extracted, err := g.fieldReferences(fld, p, g.referenceFlagPrefix(flagPrefix, fld))
 			if err != nil {
 				// TODO: warn here
 				continue
 			}
 
-			merged := orderedmap.New[string, *projscan.Field]()
+			merged := orderedmap.New[string, *Reference]()
 			// Copy refs to merged map
 			for pair := refs.Oldest(); pair != nil; pair = pair.Next() {
 				merged.Set(pair.Key, pair.Value)
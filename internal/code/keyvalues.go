@@ -0,0 +1,33 @@
+package code
+
+import (
+	"strings"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// KeyValueTag is the struct tag that reads a slice of structs from key-value pairs, as a map is, naming the string
+// fields of the items holding the key and the value, as in `keyvalue:"Key,Value"`.
+const KeyValueTag = "keyvalue"
+
+// KeyValue names the string fields holding the key and the value of the items of a slice of structs read from
+// key-value pairs.
+type KeyValue struct {
+	Key   string // Name of the field holding the key
+	Value string // Name of the field holding the value
+}
+
+// KeyValueOf returns the fields holding the key and the value of the items of the given field, set in its `keyvalue`
+// struct tag or known for the struct of the items. It returns false if the field is not read from key-value pairs.
+func KeyValueOf(field *projscan.Field) (*KeyValue, bool) {
+	if value, ok := field.Tag.Lookup(KeyValueTag); ok {
+		key, val, _ := strings.Cut(value, ",")
+		return &KeyValue{Key: strings.TrimSpace(key), Value: strings.TrimSpace(val)}, true
+	}
+
+	if key, value, ok := field.KnownKeyValue(); ok {
+		return &KeyValue{Key: key, Value: value}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,59 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// TimeLayoutTag is the struct tag used to override the layout of time.Time fields.
+const TimeLayoutTag = "layout"
+
+// TimeLayout describes how the string value of a time.Time flag is parsed.
+type TimeLayout struct {
+	Name        string // Name of the layout as written in the struct tag
+	Layout      string // Go reference layout, empty for Unix timestamps
+	Description string // Human-readable description of the accepted values
+}
+
+// Unix returns true if the layout expects Unix timestamps in seconds.
+func (l *TimeLayout) Unix() bool {
+	return l.Layout == ""
+}
+
+// Statement returns the expression of the reference layout, using the time package constant when there is one.
+func (l *TimeLayout) Statement() *jen.Statement {
+	switch l.Name {
+	case "RFC3339", "RFC3339Nano":
+		return jen.Qual("time", l.Name)
+	default:
+		return jen.Lit(l.Layout)
+	}
+}
+
+var namedTimeLayouts = map[string]*TimeLayout{
+	"rfc3339":     {Name: "RFC3339", Layout: "2006-01-02T15:04:05Z07:00", Description: "a timestamp in RFC3339 format such as 2006-01-02T15:04:05Z07:00"},
+	"rfc3339nano": {Name: "RFC3339Nano", Layout: "2006-01-02T15:04:05.999999999Z07:00", Description: "a timestamp in RFC3339 format such as 2006-01-02T15:04:05.999999999Z07:00"},
+	"dateonly":    {Name: "DateOnly", Layout: "2006-01-02", Description: "a date such as 2006-01-02"},
+	"datetime":    {Name: "DateTime", Layout: "2006-01-02 15:04:05", Description: "a date and time such as \"2006-01-02 15:04:05\""},
+	"unix":        {Name: "Unix", Layout: "", Description: "a Unix timestamp in seconds such as 1136214245"},
+}
+
+// TimeLayoutOf returns the layout of the given time.Time field. The layout is RFC3339 unless the field has a `layout`
+// struct tag naming one of RFC3339, RFC3339Nano, DateOnly, DateTime or Unix, or holding a custom Go reference layout.
+func TimeLayoutOf(field *projscan.Field) *TimeLayout {
+	name := strings.TrimSpace(field.Tag.Get(TimeLayoutTag))
+	if name == "" {
+		return namedTimeLayouts["rfc3339"]
+	}
+
+	key := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(name))
+	if layout, ok := namedTimeLayouts[key]; ok {
+		return layout
+	}
+
+	return &TimeLayout{Name: name, Layout: name, Description: fmt.Sprintf("a timestamp in the %q layout", name)}
+}
@@ -2,11 +2,13 @@ package code
 
 import (
 	"path"
+	"strings"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 
 	"github.com/dave/jennifer/jen"
 	changecase "github.com/ku/go-change-case"
+	"github.com/samber/lo"
 
 	"github.com/totvs-cloud/pflagstruct/projscan"
 )
@@ -15,6 +17,9 @@ type SetterMethod struct {
 	FlagsBuilderName string
 	Struct           *projscan.Struct
 	Flags            *orderedmap.OrderedMap[string, []*projscan.Field]
+	Backend          Backend
+	EnvPrefix        string
+	Optional         []string // Flags of the fields enclosed in structs behind pointers
 }
 
 func (s *SetterMethod) MethodName() string {
@@ -29,23 +34,343 @@ func (s *SetterMethod) Statement() *jen.Statement {
 	for pair := s.Flags.Oldest(); pair != nil; pair = pair.Next() {
 		prefix, fields := pair.Key, pair.Value
 		for _, field := range fields {
-			calls = append(calls, (&SetterCall{
-				Prefix: prefix,
-				Struct: s.Struct,
-				Field:  field,
-			}).Statement())
+			call := &SetterCall{
+				Prefix:    prefix,
+				EnvPrefix: s.EnvPrefix,
+				Struct:    s.Struct,
+				Field:     field,
+				Optional:  lo.Contains(s.Optional, flagName(prefix, field)),
+			}
+
+			calls = append(calls, call.Statement())
+			calls = append(calls, s.Backend.Marks(call)...)
 		}
 	}
 
 	return jen.Func().Params(receiver).Id(s.MethodName()).Params().Block(calls...)
 }
 
+// DefaultsSetterMethod registers the flags with the default values read from a struct at runtime, instead of the
+// zero values or the values in the `default` struct tags.
+type DefaultsSetterMethod struct {
+	FlagsBuilderName string
+	Struct           *projscan.Struct
+	Declarations     []*FlagDeclaration
+	Backend          Backend
+	EnvPrefix        string
+}
+
+func (s *DefaultsSetterMethod) MethodName() string {
+	return changecase.Camel(path.Join("SetUp", s.Struct.Name, "with", "defaults"))
+}
+
+func (s *DefaultsSetterMethod) Statement() *jen.Statement {
+	receiver := jen.Id("cf").Op("*").Id(s.FlagsBuilderName)
+	args := []jen.Code{
+		jen.Id("defaults").Op("*").Qual(s.Struct.Package.Path, s.Struct.Name),
+	}
+
+	calls := []jen.Code{
+		jen.If(jen.Id("defaults").Op("==").Nil()).Block(
+			jen.Id("cf").Dot((&SetterMethod{Struct: s.Struct}).MethodName()).Call(),
+			jen.Return(),
+		),
+	}
+
+	// variables holding the nested structs, keyed by their Go path
+	nested := map[string]string{"": "defaults"}
+
+	for _, declaration := range s.Declarations {
+		goPath, parent := "", "defaults"
+		for _, field := range declaration.Parents {
+			goPath = path.Join(goPath, field.Name)
+
+			name, ok := nested[goPath]
+			if !ok {
+				name = changecase.Camel(path.Join(goPath, "defaults"))
+				calls = append(calls, nestedDefaults(name, parent, field)...)
+				nested[goPath] = name
+			}
+
+			parent = name
+		}
+
+		call := &SetterCall{Prefix: declaration.Prefix, EnvPrefix: s.EnvPrefix, Struct: s.Struct, Field: declaration.Field, Optional: declaration.OptionalPath() != ""}
+
+		statements, value := RuntimeDefaultOf(declaration.Field, jen.Id(parent).Dot(declaration.Field.Name), changecase.Camel(path.Join(goPath, declaration.Field.Name, "default")))
+		if KindOf(declaration.Field) == FieldKindValue {
+			value = call.FlagValueOf(value)
+		}
+
+		calls = append(calls, statements...)
+		calls = append(calls, call.Registration(value))
+		calls = append(calls, s.Backend.Marks(call)...)
+	}
+
+	return jen.Func().Params(receiver).Id(s.MethodName()).Params(args...).Block(calls...)
+}
+
+// nestedDefaults returns the statements declaring a variable that points to the given nested struct of the parent
+// variable, falling back to the zero value of the struct when the field is a nil pointer.
+func nestedDefaults(name, parent string, field *projscan.Field) []jen.Code {
+	if !field.Pointer {
+		return []jen.Code{jen.Id(name).Op(":=").Op("&").Id(parent).Dot(field.Name)}
+	}
+
+	return []jen.Code{
+		jen.Id(name).Op(":=").Id(parent).Dot(field.Name),
+		jen.If(jen.Id(name).Op("==").Nil()).Block(
+			jen.Id(name).Op("=").New(jen.Qual(field.StructRef.Package.Path, field.StructRef.Name)),
+		),
+	}
+}
+
+// RequiredFlagsMethod checks that every required flag was set on the command line, reporting all the missing ones at
+// once, as cobra does. The required flags of a struct behind a pointer are only checked when one of the flags of the
+// struct was set, since the struct is left nil otherwise.
+type RequiredFlagsMethod struct {
+	FlagsBuilderName string
+	Struct           *projscan.Struct
+	Declarations     []*FlagDeclaration
+}
+
+func (r *RequiredFlagsMethod) MethodName() string {
+	return changecase.Camel(path.Join("check", r.Struct.Name, "required", "flags"))
+}
+
+func (r *RequiredFlagsMethod) Statement() *jen.Statement {
+	receiver := jen.Id("cf").Op("*").Id(r.FlagsBuilderName)
+
+	// required flags by the path of the optional struct enclosing them, empty for the ones always required
+	required := orderedmap.New[string, []jen.Code]()
+	for _, declaration := range r.Declarations {
+		if FlagTagOf(declaration.Field).Required {
+			flags, _ := required.Get(declaration.OptionalPath())
+			required.Set(declaration.OptionalPath(), append(flags, jen.Lit(declaration.Flag)))
+		}
+	}
+
+	calls := []jen.Code{
+		jen.Id("missing").Op(":=").Make(jen.Index().String(), jen.Lit(0)),
+		jen.Id("check").Op(":=").Func().Params(jen.Id("names").Op("...").String()).Block(
+			jen.For(jen.List(jen.Id("_"), jen.Id("name")).Op(":=").Range().Id("names")).Block(
+				jen.If(jen.Op("!").Id("cf").Dot("flags").Dot("Changed").Call(jen.Id("name"))).Block(
+					jen.Id("missing").Op("=").Append(jen.Id("missing"), jen.Qual("strconv", "Quote").Call(jen.Id("name"))),
+				),
+			),
+		),
+	}
+
+	for pair := required.Oldest(); pair != nil; pair = pair.Next() {
+		optional, flags := pair.Key, pair.Value
+		if optional == "" {
+			calls = append(calls, jen.Id("check").Call(flags...))
+			continue
+		}
+
+		var set *jen.Statement
+		for _, declaration := range r.Declarations {
+			if !strings.HasPrefix(declaration.GoPath(), optional+".") {
+				continue
+			}
+
+			changed := jen.Id("cf").Dot("flags").Dot("Changed").Call(jen.Lit(declaration.Flag))
+			if set == nil {
+				set = changed
+			} else {
+				set = set.Op("||").Add(changed)
+			}
+		}
+
+		calls = append(calls, jen.If(set).Block(jen.Id("check").Call(flags...)))
+	}
+
+	return jen.Func().Params(receiver).Id(r.MethodName()).Params().Error().Block(append(calls,
+		jen.If(jen.Len(jen.Id("missing")).Op(">").Lit(0)).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("required flag(s) %s not set"), jen.Qual("strings", "Join").Call(jen.Id("missing"), jen.Lit(", ")))),
+		),
+		jen.Return(jen.Nil()),
+	)...)
+}
+
+// KeyValuePairsMethod joins the comma-separated pieces of the key-value pairs of maps, of slices of structs read from
+// key-value pairs and of the items of slices of structs, and splits the pairs on their first equals sign so that values
+// can hold equals signs, such as query=a=b. Commas can be kept in the values by quoting the whole pair, as in
+// "key=a,b", by quoting the value, as in key="a,b", or by escaping them with a backslash, as in key=a\,b.
+type KeyValuePairsMethod struct {
+	FlagsBuilderName string
+}
+
+func (k *KeyValuePairsMethod) MethodName() string {
+	return "parseKeyValuePairs"
+}
+
+func (k *KeyValuePairsMethod) Statement() *jen.Statement {
+	receiver := jen.Id("cf").Op("*").Id(k.FlagsBuilderName)
+	returns := []jen.Code{
+		jen.Index().Index(jen.Lit(2)).String(),
+		jen.Error(),
+	}
+
+	more := jen.Id("i").Op("+").Lit(1).Op("<").Len(jen.Id("items"))
+	next := []jen.Code{
+		jen.Id("i").Op("++"),
+		jen.Id("item").Op("+=").Lit(",").Op("+").Id("items").Index(jen.Id("i")),
+	}
+	unterminated := jen.Qual("strings", "Count").Call(jen.Id("item"), jen.Lit(`"`)).Op("%").Lit(2).Op("==").Lit(1)
+
+	return jen.Func().Params(receiver).Id(k.MethodName()).Params(jen.Id("items").Index().String()).Params(returns...).Block(
+		jen.Id("pairs").Op(":=").Make(jen.Index().Index(jen.Lit(2)).String(), jen.Lit(0), jen.Len(jen.Id("items"))),
+		jen.For(jen.Id("i").Op(":=").Lit(0), jen.Id("i").Op("<").Len(jen.Id("items")), jen.Id("i").Op("++")).Block(
+			jen.Id("item").Op(":=").Id("items").Index(jen.Id("i")),
+			jen.Comment("splitting the values on every comma leaves quoted pairs and values in pieces"),
+			jen.If(
+				jen.List(jen.Id("_"), jen.Id("value"), jen.Id("_")).Op(":=").Qual("strings", "Cut").Call(jen.Id("item"), jen.Lit("=")),
+				jen.Qual("strings", "HasPrefix").Call(jen.Id("item"), jen.Lit(`"`)).Op("||").Qual("strings", "HasPrefix").Call(jen.Id("value"), jen.Lit(`"`)),
+			).Block(
+				jen.For(unterminated.Clone().Op("&&").Add(more.Clone())).Block(next...),
+				jen.If(unterminated.Clone()).Block(
+					jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("%q has an unterminated quote"), jen.Id("item"))),
+				),
+			),
+			jen.For(more.Clone().Op("&&").Qual("strings", "HasSuffix").Call(jen.Id("item"), jen.Lit(`\`))).Block(
+				jen.Id("item").Op("=").Qual("strings", "TrimSuffix").Call(jen.Id("item"), jen.Lit(`\`)),
+				next[0], next[1],
+			),
+			jen.If(quoted("item")).Block(
+				jen.Id("item").Op("=").Id("item").Index(jen.Lit(1), jen.Len(jen.Id("item")).Op("-").Lit(1)),
+			),
+			jen.List(jen.Id("key"), jen.Id("value"), jen.Id("ok")).Op(":=").Qual("strings", "Cut").Call(jen.Id("item"), jen.Lit("=")),
+			jen.If(jen.Op("!").Id("ok")).Block(
+				jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("%q is not a key-value pair such as key=value"), jen.Id("item"))),
+			),
+			jen.If(quoted("value")).Block(
+				jen.Id("value").Op("=").Id("value").Index(jen.Lit(1), jen.Len(jen.Id("value")).Op("-").Lit(1)),
+			),
+			jen.Id("pairs").Op("=").Append(jen.Id("pairs"), jen.Index(jen.Lit(2)).String().Values(jen.Id("key"), jen.Id("value"))),
+		),
+		jen.Return().List(jen.Id("pairs"), jen.Nil()),
+	)
+}
+
+// quoted returns the condition that holds when the string of the given variable is enclosed in double quotes.
+func quoted(name string) *jen.Statement {
+	return jen.Len(jen.Id(name)).Op(">").Lit(1).Op("&&").
+		Qual("strings", "HasPrefix").Call(jen.Id(name), jen.Lit(`"`)).Op("&&").
+		Qual("strings", "HasSuffix").Call(jen.Id(name), jen.Lit(`"`))
+}
+
+// keyValuePairs returns the statements that read the key-value pairs of the given flag to the pairs variable.
+func keyValuePairs(flag string) []jen.Code {
+	return []jen.Code{
+		jen.List(jen.Id("items"), jen.Err()).Op(":=").Id("cf").Dot("flags").Dot("GetStringSlice").Call(jen.Lit(flag)),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+flag+"\" from command flags: %w"), jen.Err())),
+		),
+		jen.List(jen.Id("pairs"), jen.Err()).Op(":=").Id("cf").Dot((&KeyValuePairsMethod{}).MethodName()).Call(jen.Id("items")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error parsing \""+flag+"\" from command flags: %w"), jen.Err())),
+		),
+	}
+}
+
+// ApplyMethod assigns to an existing struct the fields whose flags were set on the command line, leaving every other
+// field untouched. Nested pointers are only allocated when one of their flags was set. Required flags are not checked,
+// as the struct may already hold their fields.
+type ApplyMethod struct {
+	FlagsBuilderName string
+	Struct           *projscan.Struct
+	Declarations     []*FlagDeclaration
+}
+
+func (a *ApplyMethod) MethodName() string {
+	return changecase.Camel(path.Join("apply", a.Struct.Name, "flags"))
+}
+
+func (a *ApplyMethod) Statement() *jen.Statement {
+	receiver := jen.Id("cf").Op("*").Id(a.FlagsBuilderName)
+	args := []jen.Code{
+		jen.Id("target").Op("*").Qual(a.Struct.Package.Path, a.Struct.Name),
+	}
+
+	calls := []jen.Code{
+		jen.If(jen.Id("target").Op("==").Nil()).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("error applying command flags: nil " + a.Struct.Name))),
+		),
+	}
+
+	for _, declaration := range a.Declarations {
+		calls = append(calls, jen.If(jen.Id("cf").Dot("flags").Dot("Changed").Call(jen.Lit(declaration.Flag))).Block(a.Assignment(declaration)...))
+	}
+
+	calls = append(calls, jen.Return(jen.Nil()))
+
+	return jen.Func().Params(receiver).Id(a.MethodName()).Params(args...).Error().Block(calls...)
+}
+
+// Assignment returns the statements that allocate the nil pointers enclosing the field of the given declaration and
+// assign it the value of its flag.
+func (a *ApplyMethod) Assignment(declaration *FlagDeclaration) []jen.Code {
+	statements := make([]jen.Code, 0)
+
+	target, key := jen.Id("target"), ""
+	for _, parent := range declaration.Parents {
+		target = target.Clone().Dot(parent.Name)
+		key = changecase.Param(path.Join(key, parent.Name))
+
+		if parent.Pointer {
+			statements = append(statements, jen.If(target.Clone().Op("==").Nil()).Block(
+				target.Clone().Op("=").New(jen.Qual(parent.StructRef.Package.Path, parent.StructRef.Name)),
+			))
+		}
+	}
+
+	field, flag := declaration.Field, declaration.Flag
+	target = target.Clone().Dot(field.Name)
+
+	switch KindOf(field) {
+	case FieldKindNative, FieldKindDuration:
+		statements = append(statements,
+			jen.List(jen.Id("flagValue"), jen.Err()).Op(":=").Id("cf").Dot("flags").Dot(changecase.Pascal(path.Join("get", flagType(field)))).Call(jen.Lit(flag)),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+flag+"\" from command flags: %w"), jen.Err())),
+			),
+		)
+
+		switch {
+		case needsConversion(field):
+			statements = append(statements, enumValidation(field, flag, "flagValue", nil)...)
+			if field.Array {
+				// converted items are appended to the field, which must not keep the items it had
+				statements = append(statements, target.Clone().Op("=").Make(jen.Index().Add(convertedType(field)), jen.Lit(0), jen.Len(jen.Id("flagValue"))))
+			}
+
+			statements = append(statements, convertedAssignment(target, field, flag, "flagValue", nil)...)
+		case field.Pointer:
+			statements = append(statements, target.Clone().Op("=").Op("&").Id("flagValue"))
+		default:
+			statements = append(statements, target.Clone().Op("=").Id("flagValue"))
+		}
+	default:
+		getter := changecase.Camel(path.Join("Get", changecase.Param(path.Join(key, field.Name))))
+		statements = append(statements,
+			jen.List(jen.Id("flagValue"), jen.Err()).Op(":=").Id("cf").Dot(getter).Call(),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			target.Clone().Op("=").Add(flagValueAssignment(field, "flagValue")),
+		)
+	}
+
+	return statements
+}
+
 type GetterMethod struct {
 	FlagsBuilderName string
 	Prefix           string
+	FlagPrefix       string
 	Struct           *projscan.Struct
 	Pointer          bool
 	Fields           []*projscan.Field
+	Declarations     []*FlagDeclaration // Flags of the struct declaring them all, telling those nested in each field
 }
 
 func (g *GetterMethod) MethodName() string {
@@ -69,6 +394,22 @@ func (g *GetterMethod) ReturnCall() *jen.Statement {
 	return jen.Return().List(jen.Id(changecase.Camel(g.Struct.Name)), jen.Nil())
 }
 
+// NestedFlags returns the flags declared for the fields of the given struct field, at any depth.
+func (g *GetterMethod) NestedFlags(field *projscan.Field) []string {
+	if KindOf(field) != FieldKindStruct {
+		return nil
+	}
+
+	prefix, flags := changecase.Param(path.Join(g.Prefix, field.Name)), make([]string, 0)
+	for _, declaration := range g.Declarations {
+		if declaration.NestedIn(prefix) {
+			flags = append(flags, declaration.Flag)
+		}
+	}
+
+	return flags
+}
+
 func (g *GetterMethod) Statement() *jen.Statement {
 	receiver := jen.Id("cf").Op("*").Id(g.FlagsBuilderName)
 	returns := []jen.Code{
@@ -80,19 +421,22 @@ func (g *GetterMethod) Statement() *jen.Statement {
 	if g.Pointer {
 		for _, field := range g.Fields {
 			calls = append(calls, (&PointerGetterCall{
-				Prefix:  g.Prefix,
-				Struct:  g.Struct,
-				Pointer: g.Pointer,
-				Field:   field,
+				Prefix:     g.Prefix,
+				FlagPrefix: g.FlagPrefix,
+				Struct:     g.Struct,
+				Pointer:    g.Pointer,
+				Field:      field,
+				Nested:     g.NestedFlags(field),
 			}).Statement())
 		}
 	} else {
 		for _, field := range g.Fields {
 			calls = append(calls, (&GetterCall{
-				Prefix:  g.Prefix,
-				Struct:  g.Struct,
-				Pointer: g.Pointer,
-				Field:   field,
+				Prefix:     g.Prefix,
+				FlagPrefix: g.FlagPrefix,
+				Struct:     g.Struct,
+				Pointer:    g.Pointer,
+				Field:      field,
 			}).Statement())
 		}
 	}
@@ -102,10 +446,14 @@ func (g *GetterMethod) Statement() *jen.Statement {
 	return jen.Func().Params(receiver).Id(g.MethodName()).Params().Params(returns...).Block(calls...)
 }
 
+// TagsGetterMethod reads a slice of structs from key-value pairs, as a map is, setting the fields of the items named by
+// KeyValue.
 type TagsGetterMethod struct {
 	FlagsBuilderName string
 	Prefix           string
+	FlagPrefix       string
 	Struct           *projscan.Struct
+	KeyValue         *KeyValue
 	Pointer          bool
 	ArrayPointer     bool
 }
@@ -124,16 +472,20 @@ func (t *TagsGetterMethod) Initialization() *jen.Statement {
 		id = jen.Index().Op("*")
 	}
 
-	return jen.Id("resultingTags").Op(":=").Id("make").Call(id.Qual(t.Struct.Package.Path, t.Struct.Name), jen.Lit(0), jen.Id("len").Call(jen.Id("tagStrList")))
+	return jen.Id("resultingTags").Op(":=").Id("make").Call(id.Qual(t.Struct.Package.Path, t.Struct.Name), jen.Lit(0), jen.Len(jen.Id("pairs")))
 }
 
 func (t *TagsGetterMethod) ReturnType() *jen.Statement {
-	id := jen.Id(changecase.Camel(t.Struct.Name))
+	element := jen.Qual(t.Struct.Package.Path, t.Struct.Name)
 	if t.Pointer {
-		return id.Index().Op("*").Qual(t.Struct.Package.Path, t.Struct.Name)
+		element = jen.Op("*").Add(element)
+	}
+
+	if t.ArrayPointer {
+		return jen.Op("*").Index().Add(element)
 	}
 
-	return id.Index().Qual(t.Struct.Package.Path, t.Struct.Name)
+	return jen.Index().Add(element)
 }
 
 func (t *TagsGetterMethod) ResultAssignment() *jen.Statement {
@@ -145,47 +497,43 @@ func (t *TagsGetterMethod) ResultAssignment() *jen.Statement {
 }
 
 func (t *TagsGetterMethod) Flag() string {
-	return changecase.Param(path.Join(t.Prefix))
+	return t.FlagPrefix
 }
 
 func (t *TagsGetterMethod) Statement() *jen.Statement {
 	receiver := jen.Id("cf").Op("*").Id(t.FlagsBuilderName)
 
-	calls := []jen.Code{
-		jen.List(jen.Id("tagStrList"), jen.Id("err")).Op(":=").Id("cf").Dot("flags").Dot("GetStringSlice").Call(jen.Lit(t.Flag())),
-		jen.If(jen.Id("err").Op("!=").Nil()).Block(jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+t.Flag()+"\" from command flags: %w"), jen.Id("err")))),
+	calls := append(keyValuePairs(t.Flag()),
 		t.Initialization(),
-		jen.For(jen.List(jen.Id("_"),
-			jen.Id("tagStr")).Op(":=").Range().Id("tagStrList")).Block(jen.Id("parts").Op(":=").Qual("strings", "Split").Call(jen.Id("tagStr"),
-			jen.Lit("=")),
-			jen.If(jen.Id("len").Call(jen.Id("parts")).Op("!=").Lit(2)).Block(jen.Return().List(jen.Nil(),
-				jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+t.Flag()+"\" from command flags: invalid format: %s"), jen.Id("tagStr")))),
-			jen.Id("resultingTags").Op("=").Id("append").Call(jen.Id("resultingTags"),
-				t.ResultAssignment().Values(jen.Id("Name").Op(":").Id("parts").Index(jen.Lit(0)), jen.Id("Value").Op(":").Id("parts").Index(jen.Lit(1))))),
+		jen.For(jen.List(jen.Id("_"), jen.Id("pair")).Op(":=").Range().Id("pairs")).Block(
+			jen.Id("resultingTags").Op("=").Append(jen.Id("resultingTags"),
+				t.ResultAssignment().Values(jen.Id(t.KeyValue.Key).Op(":").Id("pair").Index(jen.Lit(0)), jen.Id(t.KeyValue.Value).Op(":").Id("pair").Index(jen.Lit(1)))),
+		),
+	)
+
+	returns := []jen.Code{
+		t.ReturnType(),
+		jen.Error(),
 	}
 
-	var returns []jen.Code
 	if !t.ArrayPointer {
-		returns = []jen.Code{
-			jen.Index().Op("*").Qual(t.Struct.Package.Path, t.Struct.Name),
-			jen.Error(),
-		}
-
 		calls = append(calls, jen.Return().List(jen.Id("resultingTags"), jen.Nil()))
 	} else {
-		returns = []jen.Code{
-			jen.Op("*").Index().Op("*").Qual(t.Struct.Package.Path, t.Struct.Name),
-			jen.Error(),
-		}
 		calls = append(calls, jen.Return().List(jen.Op("&").Id("resultingTags"), jen.Nil()))
 	}
 
 	return jen.Func().Params(receiver).Id(t.MethodName()).Params().Params(returns...).Block(calls...)
 }
 
+// MapGetterMethod reads a map keyed by strings from the key-value pairs of a flag set, converting the keys and the
+// values to the types of the map. Keys and values of named types are checked against the constants of their type when
+// it is an enumeration. The values of maps holding slices of strings are appended to the slice of their key, so a key
+// can be repeated.
 type MapGetterMethod struct {
 	FlagsBuilderName string
 	Prefix           string
+	FlagPrefix       string
+	Field            *projscan.Field // Map field, whose keys and values are described by its MapKey and MapValue
 	Pointer          bool
 }
 
@@ -194,38 +542,503 @@ func (t *MapGetterMethod) MethodName() string {
 }
 
 func (t *MapGetterMethod) Flag() string {
-	return changecase.Param(path.Join(t.Prefix))
+	return t.FlagPrefix
+}
+
+// Key returns the type of the keys of the map.
+func (t *MapGetterMethod) Key() *jen.Statement {
+	if t.Field.MapKey.TypeRef != nil {
+		return convertedType(t.Field.MapKey)
+	}
+
+	return jen.String()
+}
+
+// Value returns the type of the values of the map.
+func (t *MapGetterMethod) Value() *jen.Statement {
+	switch valueType, _ := StringMapValueOf(t.Field); {
+	case valueType == StringSliceMapValue:
+		return jen.Index().String()
+	case valueType == projscan.DurationFieldType:
+		return jen.Qual("time", "Duration")
+	default:
+		return convertedType(t.Field.MapValue)
+	}
+}
+
+// Failure returns the statement making the getter return an error about the current key-value pair, formatted with the
+// given message and arguments.
+func (t *MapGetterMethod) Failure(msg string, args ...jen.Code) *jen.Statement {
+	return jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(append([]jen.Code{jen.Lit("error parsing \"" + t.Flag() + "\" from command flags: " + msg)}, args...)...))
+}
+
+// Assignment returns the statements that convert the key and the value of a key-value pair to the types of the map and
+// store the value under its key.
+func (t *MapGetterMethod) Assignment(resultingMap string) []jen.Code {
+	keyField, valueField := t.Field.MapKey, t.Field.MapValue
+	statements := make([]jen.Code, 0)
+
+	key := jen.Id("key")
+	if keyField.TypeRef != nil {
+		if keyField.TypeRef.IsEnum() {
+			allowed := strings.ReplaceAll(strings.Join(keyField.TypeRef.ValueList(), ", "), "%", "%%")
+			statements = append(statements, jen.If(invalidEnumCondition(keyField, "key")).Block(
+				t.Failure("invalid key %q, allowed keys are "+allowed, jen.Id("key")),
+			))
+		}
+
+		key = convertedType(keyField).Call(key)
+	}
+
+	target := jen.Id(resultingMap).Index(key)
+	if valueType, _ := StringMapValueOf(t.Field); valueType == StringSliceMapValue {
+		return append(statements, target.Clone().Op("=").Append(target.Clone(), jen.Id("value")))
+	}
+
+	parse, parsedType := scalarParser(valueField, "value")
+
+	parsed := "value"
+	if parse != nil {
+		parsed = "parsed"
+		statements = append(statements,
+			jen.List(jen.Id(parsed), jen.Err()).Op(":=").Add(parse),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				t.Failure("invalid value of key %q: %w", jen.Id("key"), jen.Err()),
+			),
+		)
+	}
+
+	if valueField.TypeRef != nil && valueField.TypeRef.IsEnum() {
+		verb := "%v"
+		if valueField.Type == projscan.FieldTypeString {
+			verb = "%q"
+		}
+
+		allowed := strings.ReplaceAll(strings.Join(valueField.TypeRef.ValueList(), ", "), "%", "%%")
+		statements = append(statements, jen.If(invalidEnumCondition(valueField, parsed)).Block(
+			t.Failure("invalid value "+verb+" of key %q, allowed values are "+allowed, jen.Id(parsed), jen.Id("key")),
+		))
+	}
+
+	value := jen.Id(parsed)
+	if valueField.TypeRef != nil || valueField.Type != parsedType {
+		value = convertedType(valueField).Call(value)
+	}
+
+	return append(statements, target.Op("=").Add(value))
 }
 
 func (t *MapGetterMethod) Statement() *jen.Statement {
+	const resultingFilter = "resultingFilter"
+
+	receiver := jen.Id("cf").Op("*").Id(t.FlagsBuilderName)
+	returns := []jen.Code{
+		jen.Map(t.Key()).Add(t.Value()),
+		jen.Error(),
+	}
+
+	calls := append(keyValuePairs(t.Flag()),
+		jen.Id(resultingFilter).Op(":=").Make(jen.Map(t.Key()).Add(t.Value())),
+		jen.For(jen.List(jen.Id("_"), jen.Id("pair")).Op(":=").Range().Id("pairs")).Block(
+			append([]jen.Code{
+				jen.List(jen.Id("key"), jen.Id("value")).Op(":=").List(jen.Id("pair").Index(jen.Lit(0)), jen.Id("pair").Index(jen.Lit(1))),
+			}, t.Assignment(resultingFilter)...)...,
+		),
+		jen.Return().List(jen.Id(resultingFilter), jen.Nil()),
+	)
+
+	return jen.Func().Params(receiver).Id(t.MethodName()).Params().Params(returns...).Block(calls...)
+}
+
+// StructSliceGetterMethod reads a slice of structs from a flag set once per item, either to key-value pairs separated
+// by commas, whose keys are the flag names the fields of the items would have, or to a JSON object. The pairs are split
+// as the ones of maps are. Only the fields of basic types and durations can be set with key-value pairs, converted to
+// the type of each field.
+type StructSliceGetterMethod struct {
+	FlagsBuilderName string
+	Prefix           string
+	FlagPrefix       string
+	Field            *projscan.Field
+	Fields           []*projscan.Field // Fields of the struct of the items
+}
+
+func (t *StructSliceGetterMethod) MethodName() string {
+	return changecase.Camel(path.Join("Get", t.Prefix))
+}
+
+func (t *StructSliceGetterMethod) Flag() string {
+	return t.FlagPrefix
+}
+
+// Element returns the type of the items of the slice.
+func (t *StructSliceGetterMethod) Element() *jen.Statement {
+	if t.Field.Pointer {
+		return jen.Op("*").Qual(t.Field.StructRef.Package.Path, t.Field.StructRef.Name)
+	}
+
+	return jen.Qual(t.Field.StructRef.Package.Path, t.Field.StructRef.Name)
+}
+
+func (t *StructSliceGetterMethod) ReturnType() *jen.Statement {
+	if t.Field.ArrayPointer {
+		return jen.Op("*").Index().Add(t.Element())
+	}
+
+	return jen.Index().Add(t.Element())
+}
+
+// PairFields returns the fields of the items that can be set with key-value pairs.
+func (t *StructSliceGetterMethod) PairFields() []*projscan.Field {
+	fields := make([]*projscan.Field, 0, len(t.Fields))
+	for _, field := range t.Fields {
+		if kind := KindOf(field); (kind == FieldKindNative || kind == FieldKindDuration) && !field.Array {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// Failure returns the statement returning the error reported for the item being parsed, formatted with the given
+// message and arguments.
+func (t *StructSliceGetterMethod) Failure(msg string, args ...jen.Code) *jen.Statement {
+	format := "error parsing \"" + t.Flag() + "\" from command flags: item %d (%s): " + msg
+
+	return jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(append([]jen.Code{jen.Lit(format), jen.Id("i").Op("+").Lit(1), jen.Id("itemStr")}, args...)...))
+}
+
+// PairAssignment returns the statements that convert the value of a key-value pair to the type of the given field and
+// assign it to the item.
+func (t *StructSliceGetterMethod) PairAssignment(field *projscan.Field) []jen.Code {
+	key := flagSegment(field)
+	statements := make([]jen.Code, 0)
+
+	parse, parsedType := scalarParser(field, "value")
+
+	parsed := "value"
+	if parse != nil {
+		parsed = "parsed"
+		statements = append(statements,
+			jen.List(jen.Id(parsed), jen.Err()).Op(":=").Add(parse),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				t.Failure("invalid value of "+key+": %w", jen.Err()),
+			),
+		)
+	}
+
+	if field.TypeRef != nil && field.TypeRef.IsEnum() {
+		verb := "%v"
+		if field.Type == projscan.FieldTypeString {
+			verb = "%q"
+		}
+
+		allowed := strings.ReplaceAll(strings.Join(field.TypeRef.ValueList(), ", "), "%", "%%")
+		statements = append(statements, jen.If(invalidEnumCondition(field, parsed)).Block(
+			t.Failure("invalid value "+verb+" of "+key+", allowed values are "+allowed, jen.Id(parsed)),
+		))
+	}
+
+	value := jen.Id(parsed)
+	if field.TypeRef != nil || field.Type != parsedType {
+		value = convertedType(field).Call(value)
+	}
+
+	target := jen.Id("item").Dot(field.Name)
+	if field.Pointer {
+		return append(statements,
+			jen.Id("converted").Op(":=").Add(value),
+			target.Op("=").Op("&").Id("converted"),
+		)
+	}
+
+	return append(statements, target.Op("=").Add(value))
+}
+
+// scalarParser returns the call that parses the string held by src as strconv does, to 64 bits wide numbers converted
+// to the type of the field afterwards, along with the type of the parsed value. Strings are not parsed, so the call is
+// nil.
+func scalarParser(field *projscan.Field, src string) (*jen.Statement, projscan.FieldType) {
+	switch {
+	case KindOf(field) == FieldKindDuration:
+		return jen.Qual("time", "ParseDuration").Call(jen.Id(src)), projscan.DurationFieldType
+	case field.Type == projscan.FieldTypeBool:
+		return jen.Qual("strconv", "ParseBool").Call(jen.Id(src)), projscan.FieldTypeBool
+	case field.Type == projscan.FieldTypeFloat32, field.Type == projscan.FieldTypeFloat64:
+		return jen.Qual("strconv", "ParseFloat").Call(jen.Id(src), jen.Lit(bitSize(field.Type))), projscan.FieldTypeFloat64
+	case strings.HasPrefix(field.Type.String(), "uint"):
+		return jen.Qual("strconv", "ParseUint").Call(jen.Id(src), jen.Lit(0), jen.Lit(bitSize(field.Type))), projscan.FieldTypeUint64
+	case strings.HasPrefix(field.Type.String(), "int"):
+		return jen.Qual("strconv", "ParseInt").Call(jen.Id(src), jen.Lit(0), jen.Lit(bitSize(field.Type))), projscan.FieldTypeInt64
+	}
+
+	return nil, projscan.FieldTypeString
+}
+
+func (t *StructSliceGetterMethod) Statement() *jen.Statement {
 	const (
-		filterStrList   = "filterStrList"
-		resultingFilter = "resultingFilter"
-		filterStr       = "filterStr"
-		parts           = "parts"
+		itemStrList    = "itemStrList"
+		itemStr        = "itemStr"
+		resultingItems = "resultingItems"
 	)
 
 	receiver := jen.Id("cf").Op("*").Id(t.FlagsBuilderName)
 	returns := []jen.Code{
-		jen.Map(jen.String()).String(),
+		t.ReturnType(),
 		jen.Error(),
 	}
 
+	fields := t.PairFields()
+	keys := make([]string, 0, len(fields))
+	cases := make([]jen.Code, 0, len(fields)+1)
+	for _, field := range fields {
+		keys = append(keys, flagSegment(field))
+		cases = append(cases, jen.Case(jen.Lit(flagSegment(field))).Block(t.PairAssignment(field)...))
+	}
+
+	var pairs []jen.Code
+	if len(fields) > 0 {
+		cases = append(cases, jen.Default().Block(
+			t.Failure("unknown key %q, expected one of: "+strings.Join(keys, ", "), jen.Id("key")),
+		))
+
+		// the pairs follow the rules of the key-value pairs of maps
+		pairs = []jen.Code{
+			jen.List(jen.Id("pairs"), jen.Err()).Op(":=").Id("cf").Dot((&KeyValuePairsMethod{}).MethodName()).Call(jen.Qual("strings", "Split").Call(jen.Id(itemStr), jen.Lit(","))),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				t.Failure("%w", jen.Err()),
+			),
+			jen.For(jen.List(jen.Id("_"), jen.Id("pair")).Op(":=").Range().Id("pairs")).Block(
+				jen.List(jen.Id("key"), jen.Id("value")).Op(":=").List(jen.Qual("strings", "TrimSpace").Call(jen.Id("pair").Index(jen.Lit(0))), jen.Id("pair").Index(jen.Lit(1))),
+				jen.Switch(jen.Id("key")).Block(cases...),
+			),
+		}
+	} else {
+		pairs = []jen.Code{t.Failure("the items can only be given as JSON objects")}
+	}
+
+	item := jen.Id("item")
+	if t.Field.Pointer {
+		item = jen.Op("&").Id("item")
+	}
+
+	resulting := jen.Id(resultingItems)
+	if t.Field.ArrayPointer {
+		resulting = jen.Op("&").Id(resultingItems)
+	}
+
 	calls := []jen.Code{
-		jen.List(jen.Id(filterStrList), jen.Id("err")).Op(":=").Id("cf").Dot("flags").Dot("GetStringSlice").Call(jen.Lit(t.Flag())),
-		jen.If(jen.Id("err").Op("!=").Nil()).Block(
-			jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+t.Flag()+"\" from command flags: %w"), jen.Id("err"))),
+		jen.List(jen.Id(itemStrList), jen.Err()).Op(":=").Id("cf").Dot("flags").Dot("GetStringArray").Call(jen.Lit(t.Flag())),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+t.Flag()+"\" from command flags: %w"), jen.Err())),
 		),
-		jen.Id(resultingFilter).Op(":=").Id("make").Call(jen.Map(jen.String()).String()),
-		jen.For(jen.List(jen.Id("_"), jen.Id(filterStr)).Op(":=").Range().Id(filterStrList)).Block(
-			jen.Id(parts).Op(":=").Qual("strings", "Split").Call(jen.Id(filterStr), jen.Lit("=")),
-			jen.If(jen.Id("len").Call(jen.Id(parts)).Op("!=").Lit(2)).Block(
-				jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+t.Flag()+"\" from command flags: %w"), jen.Id("err"))),
-			),
-			jen.Id(resultingFilter).Index(jen.Id(parts).Index(jen.Lit(0))).Op("=").Id(parts).Index(jen.Lit(1)),
+		jen.If(jen.Len(jen.Id(itemStrList)).Op("==").Lit(0)).Block(
+			jen.Return().List(jen.Nil(), jen.Nil()),
 		),
-		jen.Return().List(jen.Id(resultingFilter), jen.Nil()),
+		jen.Id(resultingItems).Op(":=").Make(jen.Index().Add(t.Element()), jen.Lit(0), jen.Len(jen.Id(itemStrList))),
+		jen.For(jen.List(jen.Id("i"), jen.Id(itemStr)).Op(":=").Range().Id(itemStrList)).Block(
+			jen.Var().Id("item").Qual(t.Field.StructRef.Package.Path, t.Field.StructRef.Name),
+			jen.If(jen.Qual("strings", "HasPrefix").Call(jen.Qual("strings", "TrimSpace").Call(jen.Id(itemStr)), jen.Lit("{"))).Block(
+				jen.If(jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Index().Byte().Call(jen.Id(itemStr)), jen.Op("&").Id("item")), jen.Err().Op("!=").Nil()).Block(
+					t.Failure("%w", jen.Err()),
+				),
+			).Else().Block(pairs...),
+			jen.Id(resultingItems).Op("=").Append(jen.Id(resultingItems), item),
+		),
+		jen.Return().List(resulting, jen.Nil()),
+	}
+
+	return jen.Func().Params(receiver).Id(t.MethodName()).Params().Params(returns...).Block(calls...)
+}
+
+type TimeGetterMethod struct {
+	FlagsBuilderName string
+	Prefix           string
+	FlagPrefix       string
+	Field            *projscan.Field
+}
+
+func (t *TimeGetterMethod) MethodName() string {
+	return changecase.Camel(path.Join("Get", t.Prefix))
+}
+
+func (t *TimeGetterMethod) Flag() string {
+	return t.FlagPrefix
+}
+
+func (t *TimeGetterMethod) ReturnType() *jen.Statement {
+	element := jen.Qual("time", "Time")
+	if t.Field.Pointer {
+		element = jen.Op("*").Qual("time", "Time")
+	}
+
+	switch {
+	case t.Field.ArrayPointer:
+		return jen.Op("*").Index().Add(element)
+	case t.Field.Array:
+		return jen.Index().Add(element)
+	default:
+		return element
+	}
+}
+
+func (t *TimeGetterMethod) ZeroValue() *jen.Statement {
+	if t.Field.Pointer || t.Field.Array {
+		return jen.Nil()
+	}
+
+	return jen.Qual("time", "Time").Values()
+}
+
+// ParseStatements returns the statements that parse the string held by src into a time.Time variable named parsed.
+func (t *TimeGetterMethod) ParseStatements(src string) []jen.Code {
+	const parsed = "parsed"
+
+	layout := TimeLayoutOf(t.Field)
+	failure := jen.If(jen.Err().Op("!=").Nil()).Block(
+		jen.Return().List(t.ZeroValue(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error parsing \""+t.Flag()+"\" from command flags: %w"), jen.Err())),
+	)
+
+	if layout.Unix() {
+		return []jen.Code{
+			jen.List(jen.Id("seconds"), jen.Err()).Op(":=").Qual("strconv", "ParseInt").Call(jen.Id(src), jen.Lit(10), jen.Lit(64)),
+			failure,
+			jen.Id(parsed).Op(":=").Qual("time", "Unix").Call(jen.Id("seconds"), jen.Lit(0)),
+		}
+	}
+
+	return []jen.Code{
+		jen.List(jen.Id(parsed), jen.Err()).Op(":=").Qual("time", "Parse").Call(layout.Statement(), jen.Id(src)),
+		failure,
+	}
+}
+
+func (t *TimeGetterMethod) Statement() *jen.Statement {
+	const (
+		timeStrList    = "timeStrList"
+		timeStr        = "timeStr"
+		resultingTimes = "resultingTimes"
+		parsed         = "parsed"
+	)
+
+	receiver := jen.Id("cf").Op("*").Id(t.FlagsBuilderName)
+	returns := []jen.Code{
+		t.ReturnType(),
+		jen.Error(),
+	}
+
+	result := jen.Id(parsed)
+	if t.Field.Pointer {
+		result = jen.Op("&").Id(parsed)
+	}
+
+	var calls []jen.Code
+	if t.Field.Array {
+		element := jen.Qual("time", "Time")
+		if t.Field.Pointer {
+			element = jen.Op("*").Qual("time", "Time")
+		}
+
+		loop := append(t.ParseStatements(timeStr), jen.Id(resultingTimes).Op("=").Append(jen.Id(resultingTimes), result))
+
+		resulting := jen.Id(resultingTimes)
+		if t.Field.ArrayPointer {
+			resulting = jen.Op("&").Id(resultingTimes)
+		}
+
+		calls = []jen.Code{
+			jen.List(jen.Id(timeStrList), jen.Err()).Op(":=").Id("cf").Dot("flags").Dot("GetStringSlice").Call(jen.Lit(t.Flag())),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+t.Flag()+"\" from command flags: %w"), jen.Err())),
+			),
+			jen.If(jen.Len(jen.Id(timeStrList)).Op("==").Lit(0)).Block(
+				jen.Return().List(jen.Nil(), jen.Nil()),
+			),
+			jen.Id(resultingTimes).Op(":=").Make(jen.Index().Add(element), jen.Lit(0), jen.Len(jen.Id(timeStrList))),
+			jen.For(jen.List(jen.Id("_"), jen.Id(timeStr)).Op(":=").Range().Id(timeStrList)).Block(loop...),
+			jen.Return().List(resulting, jen.Nil()),
+		}
+	} else {
+		calls = []jen.Code{
+			jen.List(jen.Id(timeStr), jen.Err()).Op(":=").Id("cf").Dot("flags").Dot("GetString").Call(jen.Lit(t.Flag())),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return().List(t.ZeroValue(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+t.Flag()+"\" from command flags: %w"), jen.Err())),
+			),
+			jen.If(jen.Id(timeStr).Op("==").Lit("")).Block(
+				jen.Return().List(t.ZeroValue(), jen.Nil()),
+			),
+		}
+		calls = append(calls, t.ParseStatements(timeStr)...)
+		calls = append(calls, jen.Return().List(result, jen.Nil()))
 	}
 
 	return jen.Func().Params(receiver).Id(t.MethodName()).Params().Params(returns...).Block(calls...)
 }
+
+type ValueGetterMethod struct {
+	FlagsBuilderName string
+	TextValueName    string
+	Prefix           string
+	FlagPrefix       string
+	Field            *projscan.Field
+}
+
+func (v *ValueGetterMethod) MethodName() string {
+	return changecase.Camel(path.Join("Get", v.Prefix))
+}
+
+func (v *ValueGetterMethod) Flag() string {
+	return v.FlagPrefix
+}
+
+// Assertion returns the statement that asserts the registered pflag.Value to be a pointer to the field type, binding
+// it to a variable named value and the result of the assertion to a variable named ok.
+func (v *ValueGetterMethod) Assertion() *jen.Statement {
+	if v.Field.TypeRef.PflagValue {
+		return jen.List(jen.Id("value"), jen.Id("ok")).Op(":=").Id("flag").Dot("Value").Assert(jen.Op("*").Add(convertedType(v.Field)))
+	}
+
+	return jen.List(jen.Id("value"), jen.Id("ok")).Op(":=").Id("textValue").Dot("value").Assert(jen.Op("*").Add(convertedType(v.Field)))
+}
+
+// ZeroValue returns the pflag.Value holding the zero value of the field type, as registered without a default value.
+func (v *ValueGetterMethod) ZeroValue() *jen.Statement {
+	if v.Field.TypeRef.PflagValue {
+		return jen.New(convertedType(v.Field))
+	}
+
+	return jen.Parens(jen.Op("&").Id(v.TextValueName).Values(jen.Dict{
+		jen.Id("value"): jen.New(convertedType(v.Field)),
+		jen.Id("unset"): jen.True(),
+	}))
+}
+
+func (v *ValueGetterMethod) Statement() *jen.Statement {
+	receiver := jen.Id("cf").Op("*").Id(v.FlagsBuilderName)
+	returns := []jen.Code{
+		jen.Op("*").Add(convertedType(v.Field)),
+		jen.Error(),
+	}
+
+	found := jen.If(v.Assertion(), jen.Id("ok")).Block(
+		jen.Return().List(jen.Id("value"), jen.Nil()),
+	)
+
+	if !v.Field.TypeRef.PflagValue {
+		found = jen.If(jen.List(jen.Id("textValue"), jen.Id("ok")).Op(":=").Id("flag").Dot("Value").Assert(jen.Op("*").Id(v.TextValueName)), jen.Id("ok")).Block(found)
+	}
+
+	calls := []jen.Code{
+		jen.Id("flag").Op(":=").Id("cf").Dot("flags").Dot("Lookup").Call(jen.Lit(v.Flag())),
+		jen.If(jen.Id("flag").Op("==").Nil()).Block(
+			jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+v.Flag()+"\" from command flags: flag accessed but not defined"))),
+		),
+		// flags left with the zero value of the type are not assigned, but defaults registered at runtime are
+		jen.If(jen.Op("!").Id("cf").Dot("flags").Dot("Changed").Call(jen.Lit(v.Flag())).Op("&&").Id("flag").Dot("DefValue").Op("==").Add(v.ZeroValue()).Dot("String").Call()).Block(
+			jen.Return().List(jen.Nil(), jen.Nil()),
+		),
+		found,
+		jen.Return().List(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving \""+v.Flag()+"\" from command flags: unexpected value of type %T"), jen.Id("flag").Dot("Value"))),
+	}
+
+	return jen.Func().Params(receiver).Id(v.MethodName()).Params().Params(returns...).Block(calls...)
+}
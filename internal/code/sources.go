@@ -14,6 +14,7 @@ type FlagSource struct {
 	Struct  *projscan.Struct
 	Package *projscan.Package
 	Blocks  []Block
+	Options Options
 
 	variables []string
 	imports   map[string]string
@@ -22,8 +23,10 @@ type FlagSource struct {
 func (f *FlagSource) ImportName(path, name string) {
 	if f.imports == nil {
 		f.imports = map[string]string{
-			"github.com/spf13/cobra": "cobra",
-			"github.com/spf13/pflag": "pflag",
+			"github.com/spf13/cobra":   "cobra",
+			"github.com/spf13/pflag":   "pflag",
+			"github.com/spf13/viper":   "viper",
+			"github.com/urfave/cli/v2": "cli",
 		}
 	}
 
@@ -34,7 +37,7 @@ func (f *FlagSource) ImportName(path, name string) {
 func (f *FlagSource) File() *jen.File {
 	file := jen.NewFilePathName(f.Package.Path, f.Package.Name)
 	file.HeaderComment("Code generated by pflagstruct. DO NOT EDIT.")
-	file.HeaderComment(fmt.Sprintf("//go:generate pflagstruct --package %s --struct-name %s", f.Struct.Package.Path, f.Struct.Name))
+	file.HeaderComment(fmt.Sprintf("//go:generate pflagstruct --package %s --struct-name %s%s", f.Struct.Package.Path, f.Struct.Name, f.Options.Args()))
 	file.ImportNames(f.imports)
 
 	for _, block := range f.Blocks {
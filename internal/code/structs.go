@@ -1,19 +1,73 @@
 package code
 
 import (
+	"path"
+
 	"github.com/dave/jennifer/jen"
+	changecase "github.com/ku/go-change-case"
 	"github.com/totvs-cloud/pflagstruct/projscan"
 )
 
 type FlagsBuilderStruct struct {
-	Name   string
-	Struct *projscan.Struct
+	Name    string
+	Struct  *projscan.Struct
+	Backend Backend
 }
 
 func (cfs *FlagsBuilderStruct) Statement() *jen.Statement {
 	fields := []jen.Code{
-		jen.Id("flags").Op("*").Qual("github.com/spf13/pflag", "FlagSet"),
+		jen.Id("flags").Add(cfs.Backend.Flags(cfs.Struct)),
 	}
 
 	return jen.Type().Id(cfs.Name).Struct(fields...)
 }
+
+// TextValueStruct adapts the types implementing encoding.TextUnmarshaler and encoding.TextMarshaler to pflag.Value,
+// so that they can be registered with FlagSet.Var. Values registered without a default are shown as empty until they
+// are set, so that the usage message does not advertise the text of their zero value as the default.
+type TextValueStruct struct {
+	Name string
+}
+
+// TextValueName returns the name of the adapter generated for the given struct.
+func TextValueName(st *projscan.Struct) string {
+	return changecase.Camel(path.Join(st.Name, "flags", "text", "value"))
+}
+
+func (t *TextValueStruct) Statement() *jen.Statement {
+	receiver := jen.Id("v").Op("*").Id(t.Name)
+
+	return jen.Type().Id(t.Name).Struct(
+		jen.Id("value").Interface(
+			jen.Qual("encoding", "TextUnmarshaler"),
+			jen.Qual("encoding", "TextMarshaler"),
+		),
+		jen.Id("typ").String(),
+		jen.Id("unset").Bool(),
+	).Line().Line().
+		Func().Params(receiver).Id("String").Params().String().Block(
+		jen.If(
+			jen.Id("v").Op("==").Nil().Op("||").Id("v").Dot("value").Op("==").Nil().Op("||").Id("v").Dot("unset"),
+		).Block(
+			jen.Return().Lit(""),
+		),
+		jen.List(jen.Id("text"), jen.Err()).Op(":=").Id("v").Dot("value").Dot("MarshalText").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return().Lit(""),
+		),
+		jen.Return().String().Call(jen.Id("text")),
+	).Line().Line().
+		Func().Params(receiver).Id("Set").Params(jen.Id("s").String()).Error().Block(
+		jen.If(
+			jen.Err().Op(":=").Id("v").Dot("value").Dot("UnmarshalText").Call(jen.Index().Byte().Call(jen.Id("s"))),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.Id("v").Dot("unset").Op("=").False(),
+		jen.Return(jen.Nil()),
+	).Line().Line().
+		Func().Params(receiver).Id("Type").Params().String().Block(
+		jen.Return().Id("v").Dot("typ"),
+	)
+}
@@ -0,0 +1,83 @@
+package code
+
+import (
+	"strings"
+
+	changecase "github.com/ku/go-change-case"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// FlagTagName is the struct tag that customizes the flag of a field, as in `pflag:"name,shorthand,options"`, or
+// excludes the field from the generated flags with `pflag:"-"`.
+const FlagTagName = "pflag"
+
+// FlagTagRequired is the option of the `pflag` struct tag that makes the flag required, as in `pflag:"name,required"`.
+const FlagTagRequired = "required"
+
+// FlagTagHidden is the option of the `pflag` struct tag that hides the flag from the usage message, as in
+// `pflag:"name,hidden"`.
+const FlagTagHidden = "hidden"
+
+// DeprecatedTag is the struct tag holding the message printed when a deprecated flag is used. The flag is hidden from
+// the usage message but still read by the getters.
+const DeprecatedTag = "deprecated"
+
+// ShorthandDeprecatedTag is the struct tag holding the message printed when the deprecated shorthand of a flag is used.
+const ShorthandDeprecatedTag = "shorthand-deprecated"
+
+// FlagTag holds the options of the `pflag` struct tag of a field.
+type FlagTag struct {
+	Name      string   // Name of the flag, replacing the field name in the flag names
+	Shorthand string   // One-letter abbreviation of the flag
+	Skip      bool     // Indicates whether the field is excluded from the generated flags
+	Required  bool     // Indicates whether the flag must be set on the command line
+	Hidden    bool     // Indicates whether the flag is hidden from the usage message
+	Unknown   []string // Options that are neither a shorthand nor a known option
+}
+
+// FlagTagOf parses the `pflag` struct tag of the given field. Both the name and the shorthand are optional, so
+// `pflag:",v"` only sets the shorthand, and the options may follow either of them, as in `pflag:",required"`.
+func FlagTagOf(field *projscan.Field) *FlagTag {
+	value := strings.TrimSpace(field.Tag.Get(FlagTagName))
+	if value == "-" {
+		return &FlagTag{Skip: true}
+	}
+
+	parts := strings.Split(value, ",")
+	tag := &FlagTag{Name: strings.TrimSpace(parts[0])}
+
+	for i, part := range parts[1:] {
+		switch part = strings.TrimSpace(part); {
+		case part == FlagTagRequired:
+			tag.Required = true
+		case part == FlagTagHidden:
+			tag.Hidden = true
+		case i == 0:
+			tag.Shorthand = part
+		case part != "":
+			tag.Unknown = append(tag.Unknown, part)
+		}
+	}
+
+	return tag
+}
+
+// flagSegment returns the part of the flag names contributed by the given field: the name in its `pflag` struct tag,
+// or the field name in kebab case.
+func flagSegment(field *projscan.Field) string {
+	if name := FlagTagOf(field).Name; name != "" {
+		return name
+	}
+
+	return changecase.Param(field.Name)
+}
+
+// flagName returns the name of the flag of the given field, nested in a struct whose flags are prefixed by prefix.
+func flagName(prefix string, field *projscan.Field) string {
+	if prefix == "" {
+		return flagSegment(field)
+	}
+
+	return prefix + "-" + flagSegment(field)
+}
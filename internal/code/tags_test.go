@@ -0,0 +1,36 @@
+package code
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+func TestFlagTagOf(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  reflect.StructTag
+		want *FlagTag
+	}{
+		{name: "no tag", tag: ``, want: &FlagTag{}},
+		{name: "name", tag: `pflag:"port"`, want: &FlagTag{Name: "port"}},
+		{name: "name and shorthand", tag: `pflag:"port,p"`, want: &FlagTag{Name: "port", Shorthand: "p"}},
+		{name: "shorthand only", tag: `pflag:",p"`, want: &FlagTag{Shorthand: "p"}},
+		{name: "empty segments", tag: `pflag:",,required"`, want: &FlagTag{Required: true}},
+		{name: "options without shorthand", tag: `pflag:",required,hidden"`, want: &FlagTag{Required: true, Hidden: true}},
+		{name: "options after shorthand", tag: `pflag:"port, p , hidden"`, want: &FlagTag{Name: "port", Shorthand: "p", Hidden: true}},
+		{name: "unknown options", tag: `pflag:"port,p,requird,,secret"`, want: &FlagTag{Name: "port", Shorthand: "p", Unknown: []string{"requird", "secret"}}},
+		{name: "skipped", tag: `pflag:"-"`, want: &FlagTag{Skip: true}},
+		// shorthands longer than one character are kept, to be reported by checkFlags
+		{name: "long shorthand", tag: `pflag:"port,po"`, want: &FlagTag{Name: "port", Shorthand: "po"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, FlagTagOf(&projscan.Field{Name: "Port", Tag: tt.tag}))
+		})
+	}
+}
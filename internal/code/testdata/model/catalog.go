@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type Region string
+
+type Catalog struct {
+	Enabled  map[string]bool          `json:"enabled,omitempty"`
+	Limits   map[string]int64         `json:"limits,omitempty"`
+	Headers  map[string][]string      `json:"headers,omitempty"`
+	Timeouts map[string]time.Duration `json:"timeouts,omitempty"`
+	Tiers    map[string]Tier          `json:"tiers,omitempty"`
+	Prices   map[Tier]float64         `json:"prices,omitempty" default:"free=0,standard=9.5"`
+	Owners   map[Region]string        `json:"owners,omitempty"`
+	Zones    map[int]string           `json:"zones,omitempty"`
+}
+
+// DefaultCatalog returns the defaults the flags of Catalog are registered with, holding a map of each kind.
+func DefaultCatalog() *Catalog {
+	return &Catalog{
+		Enabled:  map[string]bool{"api": true, "ui": false},
+		Limits:   map[string]int64{"cpu": 2, "memory": 512},
+		Headers:  map[string][]string{"Accept": {"text/html", "application/json"}},
+		Timeouts: map[string]time.Duration{"read": time.Second},
+		Tiers:    map[string]Tier{"alice": TierEnterprise},
+		Prices:   map[Tier]float64{TierStandard: 9.5},
+		Owners:   map[Region]string{"eu": "ops"},
+	}
+}
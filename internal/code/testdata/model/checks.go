@@ -0,0 +1,30 @@
+package model
+
+// CollidingFields declares the name flag twice, the second time with the pflag struct tag.
+type CollidingFields struct {
+	Name  string
+	Alias string `pflag:"name"`
+}
+
+// Endpoint is embedded with a prefix by CollidingPrefix.
+type Endpoint struct {
+	Host string
+}
+
+// CollidingPrefix declares a field whose flag is the one of the field of its embedded struct, prefixed with the name
+// of the struct.
+type CollidingPrefix struct {
+	Endpoint     `embed:"prefix"`
+	EndpointHost string
+}
+
+// ReservedHelp declares the help flag, registered by pflag itself.
+type ReservedHelp struct {
+	Help bool
+}
+
+// InvalidTags declares a shorthand longer than one character and misspells an option of the pflag struct tag.
+type InvalidTags struct {
+	Port    uint16 `pflag:"port,po"`
+	Verbose bool   `pflag:"verbose,v,hiden"`
+}
@@ -0,0 +1,14 @@
+package model
+
+type Credentials struct {
+	User string `json:"user"`
+}
+
+type Database struct {
+	Host        string      `json:"host"`
+	Credentials Credentials `json:"credentials"`
+}
+
+type Cluster struct {
+	Database Database `json:"database"`
+}
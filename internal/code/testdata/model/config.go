@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type Config struct {
+	Name     string            `json:"name" default:"app"`
+	Port     uint16            `json:"port" default:"8080"`
+	Timeout  time.Duration     `json:"timeout"`
+	Labels   map[string]string `json:"labels"`
+	Database Database          `json:"database"`
+}
+
+// DefaultConfig returns the defaults the flags of Config are registered with.
+func DefaultConfig() *Config {
+	return &Config{
+		Name:     "service",
+		Port:     9090,
+		Timeout:  time.Minute,
+		Labels:   map[string]string{"env": "dev"},
+		Database: Database{Host: "localhost", Credentials: Credentials{User: "admin"}},
+	}
+}
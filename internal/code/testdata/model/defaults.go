@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Level is the severity of the messages logged.
+type Level string
+
+const (
+	LevelDebug Level = "debug"
+	LevelInfo  Level = "info"
+)
+
+// Defaults declares a valid default value for each kind of field.
+type Defaults struct {
+	Port     int8            `default:"0x7f"`
+	Ports    []uint16        `default:"80,443"`
+	Timeout  time.Duration   `default:"90s"`
+	Backoffs []time.Duration `default:"1ms,2h"`
+	Since    time.Time       `default:"2024-01-02" layout:"date-only"`
+	Level    Level           `default:"info"`
+	Labels   map[string]int  `default:"a=1,\"b=2\""`
+}
+
+// BadDefaults declares a malformed default value for each kind of field.
+type BadDefaults struct {
+	Port    int8           `default:"128"`
+	Timeout time.Duration  `default:"90"`
+	Since   time.Time      `default:"02/01/2024" layout:"date-only"`
+	Level   Level          `default:"trace"`
+	Ports   []uint16       `default:"80,\"443"`
+	Labels  map[string]int `default:"a=x"`
+	Levels  map[Level]int  `default:"trace=1"`
+}
@@ -0,0 +1,14 @@
+package model
+
+// Service reads its flags from environment variables when they are not set.
+type Service struct {
+	Host  string
+	Port  uint16
+	Token string `env:"SERVICE_TOKEN"`
+	Debug bool   `env:"-"`
+}
+
+// Account requires its owner, which can be read from an environment variable instead.
+type Account struct {
+	Owner string `pflag:",,required"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Upstream is an item of the upstreams of a gateway.
+type Upstream struct {
+	Host    string
+	Port    uint16
+	Timeout time.Duration
+	Query   string
+}
+
+// Gateway reads key-value pairs into a slice of structs and a map, which follow the same rules.
+type Gateway struct {
+	Upstreams []Upstream
+	Meta      map[string]string
+}
@@ -0,0 +1,8 @@
+package model
+
+// Legacy hides and deprecates its flags, which only pflag supports.
+type Legacy struct {
+	Name    string `pflag:",n,hidden"`
+	Old     string `deprecated:"use --name"`
+	Verbose bool   `pflag:",v" shorthand-deprecated:"use --verbose"`
+}
@@ -0,0 +1,14 @@
+package model
+
+type Tier string
+
+const (
+	TierFree       Tier = "free"
+	TierStandard   Tier = "standard"
+	TierEnterprise Tier = "enterprise"
+)
+
+type Plan struct {
+	Name string `json:"name"`
+	Tier Tier   `json:"tier"`
+}
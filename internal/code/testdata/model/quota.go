@@ -0,0 +1,25 @@
+package model
+
+type Label struct {
+	Key   string
+	Value string
+}
+
+type Window struct {
+	Seconds int `json:"seconds"`
+}
+
+type Limits struct {
+	Max      *int              `json:"max,omitempty"`
+	Enabled  *bool             `json:"enabled,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Labels   []Label           `json:"labels,omitempty" keyvalue:"Key,Value"`
+	Tags     []*Label          `json:"tags,omitempty" keyvalue:"Key,Value"`
+	Notes    *[]Label          `json:"notes,omitempty" keyvalue:"Key,Value"`
+	Window   Window            `json:"window"`
+}
+
+type Quota struct {
+	Name   string  `json:"name"`
+	Limits *Limits `json:"limits"`
+}
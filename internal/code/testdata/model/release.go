@@ -0,0 +1,23 @@
+package model
+
+import "fmt"
+
+// Version is parsed from and printed as text like v1.2.
+type Version struct {
+	Major int
+	Minor int
+}
+
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("v%d.%d", v.Major, v.Minor)), nil
+}
+
+func (v *Version) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "v%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+type Release struct {
+	Name    string   `json:"name"`
+	Version *Version `json:"version,omitempty"`
+}
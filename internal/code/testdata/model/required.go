@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Proxy is optional, but its URL is required once any of its flags is set.
+type Proxy struct {
+	URL     string `pflag:",,required"`
+	Timeout time.Duration
+}
+
+// Client requires its name, and its proxy URL only when the proxy is set.
+type Client struct {
+	Name  string `pflag:",,required"`
+	Proxy *Proxy
+}
+
+// RequiredDefault declares a default value for a required flag, which would never be read.
+type RequiredDefault struct {
+	Name string `pflag:",,required" default:"client"`
+}
@@ -0,0 +1,12 @@
+package model
+
+// Metadata holds a pair under different field names than Label.
+type Metadata struct {
+	Name string
+	Data string
+}
+
+type Resource struct {
+	Labels   []Label     `json:"labels" keyvalue:"Key,Value"`
+	Metadata []*Metadata `json:"metadata" keyvalue:"Name,Data"`
+}
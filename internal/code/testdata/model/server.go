@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Server declares a field of each kind that the adapters of the flag and cli backends register.
+type Server struct {
+	Name    string `pflag:",n"`
+	Port    uint16 `default:"8080"`
+	Hosts   []string
+	Weights []int32
+	Timeout time.Duration
+	Since   time.Time `layout:"date-only"`
+	Level   Level
+	Labels  map[string]string
+	Proxy   *Proxy
+}
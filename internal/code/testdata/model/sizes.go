@@ -0,0 +1,8 @@
+package model
+
+// Sizes holds slices of sized unsigned integers, read as uints and narrowed by the generated code.
+type Sizes struct {
+	Small  []uint8  `json:"Small,omitempty"`
+	Medium []uint16 `json:"Medium,omitempty"`
+	Large  []uint32 `json:"Large,omitempty"`
+}
@@ -0,0 +1,8 @@
+package model
+
+type User struct {
+	Name     string `json:"name" pflag:",n"`
+	FullName string `json:"fullName" pflag:",f" deprecated:"use --name instead"`
+	Verbose  bool   `json:"verbose" pflag:",v" shorthand-deprecated:"use --verbose instead"`
+	Token    string `json:"token" pflag:",,hidden"`
+}
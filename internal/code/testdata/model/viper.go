@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Settings is read from viper, with keys renamed or left out by their mapstructure struct tags.
+type Settings struct {
+	Name    string
+	Timeout time.Duration
+	Labels  map[string]string
+	Proxy   *Proxy `mapstructure:"upstream"`
+	Secret  string `mapstructure:"-"`
+}
@@ -0,0 +1,177 @@
+package code
+
+import (
+	"path"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	changecase "github.com/ku/go-change-case"
+
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// ViperTag is the struct tag that viper decodes configuration keys with, naming the keys of the fields.
+const ViperTag = "mapstructure"
+
+// ViperKey returns the viper key of the field relative to the struct declaring the flags, such as quux.quuz.id. The
+// keys are the lowercase field names, or the names in their `mapstructure` struct tags, and embedded structs are
+// promoted as they are by the flags. It returns false if the field or one of the structs enclosing it is left out of
+// the configuration with `mapstructure:"-"`.
+func (d *FlagDeclaration) ViperKey() (string, bool) {
+	names := make([]string, 0, len(d.Parents)+1)
+	for _, field := range append(append(make([]*projscan.Field, 0, len(d.Parents)+1), d.Parents...), d.Field) {
+		name, options, _ := strings.Cut(field.Tag.Get(ViperTag), ",")
+
+		switch {
+		case name == "-":
+			return "", false
+		case name != "":
+			names = append(names, strings.ToLower(name))
+		case strings.Contains(options, "squash"), field.Embedded && KindOf(field) == FieldKindStruct:
+			// promoted to the enclosing struct
+		default:
+			names = append(names, strings.ToLower(field.Name))
+		}
+	}
+
+	return strings.Join(names, "."), true
+}
+
+// viperBindings returns the flags bound to viper keys, along with their keys.
+func viperBindings(declarations []*FlagDeclaration) []jen.Code {
+	bindings := make([]jen.Code, 0, len(declarations))
+	for _, declaration := range declarations {
+		if key, ok := declaration.ViperKey(); ok {
+			bindings = append(bindings, jen.Values(jen.Dict{
+				jen.Id("key"):  jen.Lit(key),
+				jen.Id("flag"): jen.Lit(declaration.Flag),
+			}))
+		}
+	}
+
+	return bindings
+}
+
+// viperBindingsRange returns the range clause iterating over the flags bound to viper keys.
+func viperBindingsRange(declarations []*FlagDeclaration) *jen.Statement {
+	return jen.List(jen.Id("_"), jen.Id("binding")).Op(":=").Range().
+		Index().Struct(jen.List(jen.Id("key"), jen.Id("flag")).String()).Values(viperBindings(declarations)...)
+}
+
+type BindViperConstructor struct {
+	Struct       *projscan.Struct
+	Declarations []*FlagDeclaration
+}
+
+func (b *BindViperConstructor) MethodName() string {
+	return changecase.Pascal(path.Join("Bind", b.Struct.Name, "flags", "to", "viper"))
+}
+
+func (b *BindViperConstructor) Statement() *jen.Statement {
+	args := []jen.Code{
+		jen.Id("v").Op("*").Qual("github.com/spf13/viper", "Viper"),
+		jen.Id("flags").Op("*").Qual("github.com/spf13/pflag", "FlagSet"),
+	}
+
+	return jen.Func().Id(b.MethodName()).Params(args...).Error().Block(
+		jen.For(viperBindingsRange(b.Declarations)).Block(
+			jen.If(jen.Err().Op(":=").Id("v").Dot("BindPFlag").Call(jen.Id("binding").Dot("key"), jen.Id("flags").Dot("Lookup").Call(jen.Id("binding").Dot("flag"))), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("error binding %q to viper key %q: %w"), jen.Id("binding").Dot("flag"), jen.Id("binding").Dot("key"), jen.Err())),
+			),
+		),
+		jen.Return(jen.Nil()),
+	)
+}
+
+// GetFromViperConstructor builds the struct from the values held by viper, whether they come from configuration files,
+// environment variables or bound flags. The values are set to a fresh set of flags and read by the flags getter, so
+// they are parsed and validated as they are on the command line.
+type GetFromViperConstructor struct {
+	Struct       *projscan.Struct
+	Declarations []*FlagDeclaration
+}
+
+func (g *GetFromViperConstructor) MethodName() string {
+	return changecase.Pascal(path.Join("Get", g.Struct.Name, "from", "viper"))
+}
+
+func (g *GetFromViperConstructor) Statement() *jen.Statement {
+	args := []jen.Code{
+		jen.Id("v").Op("*").Qual("github.com/spf13/viper", "Viper"),
+	}
+	returns := []jen.Code{
+		jen.Op("*").Qual(g.Struct.Package.Path, g.Struct.Name),
+		jen.Error(),
+	}
+
+	errorf := func(err jen.Code) *jen.Statement {
+		return jen.Qual("fmt", "Errorf").Call(jen.Lit("error retrieving %q from viper key %q: %w"), jen.Id("binding").Dot("flag"), jen.Id("binding").Dot("key"), err)
+	}
+
+	return jen.Func().Id(g.MethodName()).Params(args...).Params(returns...).Block(
+		jen.If(jen.Id("v").Op("==").Nil()).Block(
+			jen.Id("v").Op("=").Qual("github.com/spf13/viper", "GetViper").Call(),
+		),
+		jen.Id("flags").Op(":=").Qual("github.com/spf13/pflag", "NewFlagSet").Call(jen.Lit(g.Struct.Name), jen.Qual("github.com/spf13/pflag", "ContinueOnError")),
+		jen.Id((&SetUpConstructor{Struct: g.Struct}).MethodName()).Call(jen.Id("flags")),
+		jen.For(viperBindingsRange(g.Declarations)).Block(
+			jen.If(jen.Op("!").Id("v").Dot("IsSet").Call(jen.Id("binding").Dot("key"))).Block(
+				jen.Continue(),
+			),
+			jen.Id("flag").Op(":=").Id("flags").Dot("Lookup").Call(jen.Id("binding").Dot("flag")),
+			jen.List(jen.Id("slice"), jen.Id("ok")).Op(":=").Id("flag").Dot("Value").Assert(jen.Qual("github.com/spf13/pflag", "SliceValue")),
+			jen.If(jen.Op("!").Id("ok")).Block(
+				jen.If(jen.Err().Op(":=").Id("flags").Dot("Set").Call(jen.Id("binding").Dot("flag"), jen.Id("v").Dot("GetString").Call(jen.Id("binding").Dot("key"))), jen.Err().Op("!=").Nil()).Block(
+					jen.Return().List(jen.Nil(), errorf(jen.Err())),
+				),
+				jen.Continue(),
+			),
+			jen.Var().Id("items").Index().String(),
+			jen.Switch(jen.Id("value").Op(":=").Id("v").Dot("Get").Call(jen.Id("binding").Dot("key")).Assert(jen.Type())).Block(
+				jen.Case(jen.String()).Block(
+					jen.Comment("environment variables and the bound flags viper does not convert hold values such as a,b or [a,b]"),
+					jen.If(jen.Err().Op(":=").Id("flags").Dot("Set").Call(jen.Id("binding").Dot("flag"), jen.Qual("strings", "TrimSuffix").Call(jen.Qual("strings", "TrimPrefix").Call(jen.Id("value"), jen.Lit("[")), jen.Lit("]"))), jen.Err().Op("!=").Nil()).Block(
+						jen.Return().List(jen.Nil(), errorf(jen.Err())),
+					),
+					jen.Continue(),
+				),
+				jen.Case(jen.Map(jen.String()).Interface()).Block(
+					jen.Comment("maps read from configuration files are given to their flags as key=value pairs"),
+					jen.For(jen.List(jen.Id("key"), jen.Id("item")).Op(":=").Range().Id("value")).Block(
+						jen.If(jen.List(jen.Id("list"), jen.Id("ok")).Op(":=").Id("item").Assert(jen.Index().Interface()), jen.Id("ok")).Block(
+							jen.Comment("lists are given as one pair per item, to maps holding slices of strings"),
+							jen.For(jen.List(jen.Id("_"), jen.Id("element")).Op(":=").Range().Id("list")).Block(
+								jen.Id("items").Op("=").Append(jen.Id("items"), jen.Qual("fmt", "Sprintf").Call(jen.Lit("%s=%v"), jen.Id("key"), jen.Id("element"))),
+							),
+							jen.Continue(),
+						),
+						jen.Id("items").Op("=").Append(jen.Id("items"), jen.Qual("fmt", "Sprintf").Call(jen.Lit("%s=%v"), jen.Id("key"), jen.Id("item"))),
+					),
+					jen.Qual("sort", "Strings").Call(jen.Id("items")),
+				),
+				jen.Case(jen.Index().Interface()).Block(
+					jen.Comment("slices of structs read from configuration files are given to their flags as JSON objects"),
+					jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Id("value")).Block(
+						jen.If(jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Id("item").Assert(jen.Map(jen.String()).Interface()), jen.Op("!").Id("ok")).Block(
+							jen.Id("items").Op("=").Append(jen.Id("items"), jen.Qual("fmt", "Sprint").Call(jen.Id("item"))),
+							jen.Continue(),
+						),
+						jen.List(jen.Id("encoded"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(jen.Id("item")),
+						jen.If(jen.Err().Op("!=").Nil()).Block(
+							jen.Return().List(jen.Nil(), errorf(jen.Err())),
+						),
+						jen.Id("items").Op("=").Append(jen.Id("items"), jen.String().Call(jen.Id("encoded"))),
+					),
+				),
+				jen.Default().Block(
+					jen.Id("items").Op("=").Id("v").Dot("GetStringSlice").Call(jen.Id("binding").Dot("key")),
+				),
+			),
+			jen.If(jen.Err().Op(":=").Id("slice").Dot("Replace").Call(jen.Id("items")), jen.Err().Op("!=").Nil()).Block(
+				jen.Return().List(jen.Nil(), errorf(jen.Err())),
+			),
+			jen.Id("flag").Dot("Changed").Op("=").True(),
+		),
+		jen.Return(jen.Id((&GetConstructor{Struct: g.Struct, Backend: &PflagBackend{}}).MethodName()).Call(jen.Id("flags"))),
+	)
+}
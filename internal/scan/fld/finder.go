@@ -3,9 +3,13 @@ package fld
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
+	"golang.org/x/exp/slog"
 
 	"github.com/totvs-cloud/pflagstruct/internal/syntree"
 	"github.com/totvs-cloud/pflagstruct/projscan"
@@ -16,11 +20,13 @@ type Finder struct {
 	packages projscan.PackageFinder
 	projects projscan.ProjectFinder
 	structs  projscan.StructFinder
+	types    projscan.TypeFinder
+	warned   map[token.Pos]bool // Embedded fields already reported as unsupported, as structs are visited many times
 }
 
 // NewFinder creates a new instance of Finder.
-func NewFinder(packages projscan.PackageFinder, projects projscan.ProjectFinder, structs projscan.StructFinder) *Finder {
-	return &Finder{packages: packages, projects: projects, structs: structs}
+func NewFinder(packages projscan.PackageFinder, projects projscan.ProjectFinder, structs projscan.StructFinder, types projscan.TypeFinder) *Finder {
+	return &Finder{packages: packages, projects: projects, structs: structs, types: types, warned: make(map[token.Pos]bool)}
 }
 
 // FindFieldsByStruct returns a slice of fields for the given struct.
@@ -37,6 +43,39 @@ func (f *Finder) FindFieldsByStruct(st *projscan.Struct) ([]*projscan.Field, err
 	result := make([]*projscan.Field, 0)
 
 	for _, field := range st.AST.StructType.Fields.List {
+		tag, err := extractTag(field.Tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(field.Names) == 0 && f.warned[field.Type.Pos()] {
+			// embedded types already found not to be supported are not looked up again, so they are reported once
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			// it means that the field is embedded, so it is named after its type
+			built, err := f.buildField(field.Type, st, proj, &projscan.Field{
+				Name:      extractTypeName(field.Type),
+				Type:      "",
+				Doc:       extractDoc(field.Doc),
+				StructRef: nil,
+				Pointer:   false,
+				Array:     false,
+				Tag:       tag,
+				Embedded:  true,
+				Pos:       field.Type.Pos(),
+			})
+			if err != nil {
+				// embedded types that cannot be resolved, such as interfaces, are left out
+				f.warned[field.Type.Pos()] = true
+				slog.Warn("embedded field type not supported", slog.String("StructName", st.Name), slog.String("FieldType", extractTypeName(field.Type)))
+				continue
+			}
+
+			result = append(result, built)
+		}
+
 		for _, name := range field.Names {
 			built, err := f.buildField(field.Type, st, proj, &projscan.Field{
 				Name:      name.String(),
@@ -45,6 +84,8 @@ func (f *Finder) FindFieldsByStruct(st *projscan.Struct) ([]*projscan.Field, err
 				StructRef: nil,
 				Pointer:   false,
 				Array:     false,
+				Tag:       tag,
+				Pos:       name.Pos(),
 			})
 			if err != nil {
 				return nil, err
@@ -70,6 +111,10 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 			Pointer:      true,
 			Array:        field.Array,
 			ArrayPointer: field.ArrayPointer,
+			Tag:          field.Tag,
+			TypeRef:      field.TypeRef,
+			Embedded:     field.Embedded,
+			Pos:          field.Pos,
 		})
 	case *ast.ArrayType:
 		// it means that the field type is an array
@@ -81,9 +126,13 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 			Pointer:      false,
 			Array:        true,
 			ArrayPointer: field.Pointer,
+			Tag:          field.Tag,
+			TypeRef:      field.TypeRef,
+			Embedded:     field.Embedded,
+			Pos:          field.Pos,
 		})
 	case *ast.Ident:
-		// it means that the field type is either a built-in type or a struct from the same package
+		// it means that the field type is either a built-in type or a named type from the same package
 		if projscan.FieldType(x.Name).IsValid() {
 			return &projscan.Field{
 				Name:         field.Name,
@@ -93,25 +142,16 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 				Pointer:      field.Pointer,
 				Array:        field.Array,
 				ArrayPointer: field.ArrayPointer,
+				Tag:          field.Tag,
+				TypeRef:      field.TypeRef,
+				Embedded:     field.Embedded,
+				Pos:          field.Pos,
 			}, nil
 		}
 
-		structRef, err := f.structs.FindStructByDirectoryAndName(st.Package.Directory, x.Name)
-		if err != nil {
-			return nil, err
-		}
-
-		return &projscan.Field{
-			Name:         field.Name,
-			Type:         projscan.FieldType(x.Name),
-			Doc:          field.Doc,
-			StructRef:    structRef,
-			Pointer:      field.Pointer,
-			Array:        field.Array,
-			ArrayPointer: field.ArrayPointer,
-		}, nil
+		return f.buildNamedField(st.Package.Directory, x.Name, projscan.FieldType(x.Name), field)
 	case *ast.SelectorExpr:
-		// it means that the field type is a struct from another package
+		// it means that the field type is a named type from another package
 		if ident, ok := x.X.(*ast.Ident); ok {
 			path, err := syntree.WrapFile(st.AST.File).FindPackagePathByName(ident.Name)
 			if err != nil {
@@ -123,20 +163,24 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 				return nil, err
 			}
 
-			structRef, err := f.structs.FindStructByDirectoryAndName(pkg.Directory, x.Sel.Name)
-			if err != nil {
-				return nil, err
+			if pkg.Path == "std/time" && x.Sel.Name == "Duration" {
+				// time.Duration is not a struct, but pflag supports it natively
+				return &projscan.Field{
+					Name:         field.Name,
+					Type:         projscan.DurationFieldType,
+					Doc:          field.Doc,
+					StructRef:    nil,
+					Pointer:      field.Pointer,
+					Array:        field.Array,
+					ArrayPointer: field.ArrayPointer,
+					Tag:          field.Tag,
+					TypeRef:      field.TypeRef,
+					Embedded:     field.Embedded,
+					Pos:          field.Pos,
+				}, nil
 			}
 
-			return &projscan.Field{
-				Name:         field.Name,
-				Type:         projscan.FieldType(fmt.Sprintf("%s.%s", pkg.Name, x.Sel.Name)),
-				Doc:          field.Doc,
-				StructRef:    structRef,
-				Pointer:      field.Pointer,
-				Array:        field.Array,
-				ArrayPointer: field.ArrayPointer,
-			}, nil
+			return f.buildNamedField(pkg.Directory, x.Sel.Name, projscan.FieldType(fmt.Sprintf("%s.%s", pkg.Name, x.Sel.Name)), field)
 		}
 	case *ast.MapType:
 		// it means that the field type is a map
@@ -148,6 +192,10 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 			Pointer:      false,
 			Array:        false,
 			ArrayPointer: false,
+			Tag:          field.Tag,
+			TypeRef:      field.TypeRef,
+			Embedded:     field.Embedded,
+			Pos:          field.Pos,
 		})
 		if err != nil {
 			return nil, err
@@ -161,6 +209,10 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 			Pointer:      false,
 			Array:        false,
 			ArrayPointer: false,
+			Tag:          field.Tag,
+			TypeRef:      field.TypeRef,
+			Embedded:     field.Embedded,
+			Pos:          field.Pos,
 		})
 		if err != nil {
 			return nil, err
@@ -168,12 +220,18 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 
 		return &projscan.Field{
 			Name:         field.Name,
-			Type:         projscan.FieldType(fmt.Sprintf("map[%s]%s", key.Type, value.Type)),
+			Type:         projscan.FieldType(fmt.Sprintf("map[%s]%s", mapElementType(key), mapElementType(value))),
 			Doc:          field.Doc,
 			StructRef:    field.StructRef,
 			Pointer:      field.Pointer,
 			Array:        field.Array,
 			ArrayPointer: field.ArrayPointer,
+			Tag:          field.Tag,
+			TypeRef:      field.TypeRef,
+			Embedded:     field.Embedded,
+			Pos:          field.Pos,
+			MapKey:       key,
+			MapValue:     value,
 		}, nil
 	}
 
@@ -181,6 +239,101 @@ func (f *Finder) buildField(expr ast.Expr, st *projscan.Struct, proj *projscan.P
 	return nil, errors.New("field type not found")
 }
 
+// mapElementType returns the type of the keys or the values of a map as written in its declaration, such as []string
+// or *foo.Level, rather than the type of the flags of a field, which leaves slices, pointers and named types out.
+func mapElementType(field *projscan.Field) string {
+	name := field.Type.String()
+	if field.TypeRef != nil && field.TypeRef.Package != nil {
+		name = field.TypeRef.Package.Name + "." + field.TypeRef.Name
+	}
+
+	if field.Pointer {
+		name = "*" + name
+	}
+
+	if field.Array {
+		name = "[]" + name
+	}
+
+	return name
+}
+
+// buildNamedField creates a new Field for a type declared by name in the given directory. Named types whose underlying
+// type is a built-in type are typed after it, types able to parse flag values by themselves are kept as they are, and
+// any other type must be a struct.
+func (f *Finder) buildNamedField(directory, typeName string, fieldType projscan.FieldType, field *projscan.Field) (*projscan.Field, error) {
+	typeRef, err := f.types.FindTypeByDirectoryAndName(directory, typeName)
+	if err == nil && typeRef.Underlying.IsValid() {
+		// it means that the field type is a named type whose underlying type is a built-in type
+		return &projscan.Field{
+			Name:         field.Name,
+			Type:         typeRef.Underlying,
+			Doc:          field.Doc,
+			StructRef:    field.StructRef,
+			Pointer:      field.Pointer,
+			Array:        field.Array,
+			ArrayPointer: field.ArrayPointer,
+			Tag:          field.Tag,
+			TypeRef:      typeRef,
+			Embedded:     field.Embedded,
+			Pos:          field.Pos,
+		}, nil
+	}
+
+	if err != nil || !typeRef.IsFlagValue() {
+		// the type is only kept when it is able to parse flag values by itself
+		typeRef = field.TypeRef
+	} else if !typeRef.Struct {
+		// it means that the field type is a named type such as a slice that parses flag values by itself
+		return &projscan.Field{
+			Name:         field.Name,
+			Type:         fieldType,
+			Doc:          field.Doc,
+			StructRef:    field.StructRef,
+			Pointer:      field.Pointer,
+			Array:        field.Array,
+			ArrayPointer: field.ArrayPointer,
+			Tag:          field.Tag,
+			TypeRef:      typeRef,
+			Embedded:     field.Embedded,
+			Pos:          field.Pos,
+		}, nil
+	}
+
+	structRef, err := f.structs.FindStructByDirectoryAndName(directory, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &projscan.Field{
+		Name:         field.Name,
+		Type:         fieldType,
+		Doc:          field.Doc,
+		StructRef:    structRef,
+		Pointer:      field.Pointer,
+		Array:        field.Array,
+		ArrayPointer: field.ArrayPointer,
+		Tag:          field.Tag,
+		TypeRef:      typeRef,
+		Embedded:     field.Embedded,
+		Pos:          field.Pos,
+	}, nil
+}
+
+// extractTypeName returns the name of the type of an embedded field, which is also the name of the field.
+func extractTypeName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return extractTypeName(x.X)
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	case *ast.Ident:
+		return x.Name
+	}
+
+	return ""
+}
+
 // extractDoc returns the documentation text for the given ast.CommentGroup. If doc is nil, an empty string is returned.
 func extractDoc(doc *ast.CommentGroup) string {
 	if doc == nil {
@@ -194,3 +347,17 @@ func extractDoc(doc *ast.CommentGroup) string {
 
 	return strings.Join(comments, "\n")
 }
+
+// extractTag returns the struct tag for the given ast.BasicLit. If tag is nil, an empty tag is returned.
+func extractTag(tag *ast.BasicLit) (reflect.StructTag, error) {
+	if tag == nil {
+		return "", nil
+	}
+
+	value, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return reflect.StructTag(value), nil
+}
@@ -1,14 +1,22 @@
 package fld
 
 import (
+	"bytes"
 	"go/token"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/totvs-cloud/pflagstruct/internal/scan/pkg"
 	"github.com/totvs-cloud/pflagstruct/internal/scan/proj"
 	"github.com/totvs-cloud/pflagstruct/internal/scan/st"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/typ"
 	"github.com/totvs-cloud/pflagstruct/internal/syntree"
+	"github.com/totvs-cloud/pflagstruct/projscan"
+	"golang.org/x/exp/slog"
 )
 
 func TestFinder_FindFieldsByStruct(t *testing.T) {
@@ -17,7 +25,8 @@ func TestFinder_FindFieldsByStruct(t *testing.T) {
 		projsvc := proj.NewFinder(scanner)
 		pkgsvc := pkg.NewFinder(scanner, projsvc)
 		stsvc := st.NewFinder(scanner, projsvc, pkgsvc)
-		fldsvc := NewFinder(pkgsvc, projsvc, stsvc)
+		typsvc := typ.NewFinder(scanner, pkgsvc)
+		fldsvc := NewFinder(pkgsvc, projsvc, stsvc, typsvc)
 
 		st, err := stsvc.FindStructByDirectoryAndName("../../../_test/testdata/bar", "Quuz")
 		require.NoError(t, err)
@@ -31,3 +40,161 @@ func TestFinder_FindFieldsByStruct(t *testing.T) {
 		}
 	})
 }
+
+func TestFinder_FindFieldsByStruct_UnsignedIntegers(t *testing.T) {
+	fldsvc, stsvc, _ := newFinder()
+
+	st, err := stsvc.FindStructByDirectoryAndName("../../../_test/testdata/qux", "Server")
+	require.NoError(t, err)
+
+	flds, err := fldsvc.FindFieldsByStruct(st)
+	require.NoError(t, err)
+	require.Len(t, flds, 4)
+
+	require.Equal(t, projscan.FieldTypeUint32, flds[1].Type)
+	require.Equal(t, projscan.FieldTypeUint64, flds[2].Type)
+	require.True(t, flds[2].Pointer)
+	require.Equal(t, projscan.FieldTypeUint16, flds[3].Type)
+	require.True(t, flds[3].Array)
+}
+
+func TestFinder_FindFieldsByStruct_Durations(t *testing.T) {
+	if _, ok := os.LookupEnv("GOROOT"); !ok {
+		t.Setenv("GOROOT", runtime.GOROOT())
+	}
+
+	fldsvc, stsvc, _ := newFinder()
+
+	st, err := stsvc.FindStructByDirectoryAndName("../../../_test/testdata/qux", "Timeouts")
+	require.NoError(t, err)
+
+	flds, err := fldsvc.FindFieldsByStruct(st)
+	require.NoError(t, err)
+	require.Len(t, flds, 3)
+
+	for _, fld := range flds {
+		require.True(t, fld.IsDuration())
+	}
+	require.True(t, flds[1].Pointer)
+	require.True(t, flds[2].Array)
+}
+
+func TestFinder_FindFieldsByStruct_Times(t *testing.T) {
+	if _, ok := os.LookupEnv("GOROOT"); !ok {
+		t.Setenv("GOROOT", runtime.GOROOT())
+	}
+
+	fldsvc, stsvc, _ := newFinder()
+
+	st, err := stsvc.FindStructByDirectoryAndName("../../../_test/testdata/qux", "Event")
+	require.NoError(t, err)
+
+	flds, err := fldsvc.FindFieldsByStruct(st)
+	require.NoError(t, err)
+	require.Len(t, flds, 2)
+
+	require.True(t, flds[0].IsTime())
+	require.Equal(t, "Created", flds[0].Tag.Get("json"))
+	require.True(t, flds[1].IsTime())
+	require.True(t, flds[1].Pointer)
+	require.Equal(t, "DateOnly", flds[1].Tag.Get("layout"))
+}
+
+func TestFinder_FindFieldsByStruct_NamedTypes(t *testing.T) {
+	fldsvc, stsvc, _ := newFinder()
+
+	st, err := stsvc.FindStructByDirectoryAndName("../../../_test/testdata/qux", "Deployment")
+	require.NoError(t, err)
+
+	flds, err := fldsvc.FindFieldsByStruct(st)
+	require.NoError(t, err)
+	require.Len(t, flds, 5)
+
+	require.Equal(t, projscan.FieldTypeString, flds[0].Type)
+	require.Equal(t, "Region", flds[0].TypeRef.Name)
+	require.Equal(t, projscan.FieldTypeInt32, flds[1].Type)
+	require.True(t, flds[1].Pointer)
+	require.Equal(t, projscan.FieldTypeUint64, flds[2].Type)
+	require.Equal(t, "github.com/totvs-cloud/pflagstruct/_test/testdata/qux/units", flds[2].TypeRef.Package.Path)
+	require.Equal(t, projscan.FieldTypeString, flds[3].Type)
+	require.True(t, flds[3].Array)
+	require.True(t, flds[4].TypeRef.IsEnum())
+}
+
+func TestFinder_FindFieldsByStruct_FlagValues(t *testing.T) {
+	if _, ok := os.LookupEnv("GOROOT"); !ok {
+		t.Setenv("GOROOT", runtime.GOROOT())
+	}
+
+	fldsvc, stsvc, _ := newFinder()
+
+	st, err := stsvc.FindStructByDirectoryAndName("../../../_test/testdata/qux", "Network")
+	require.NoError(t, err)
+
+	flds, err := fldsvc.FindFieldsByStruct(st)
+	require.NoError(t, err)
+	require.Len(t, flds, 4)
+
+	require.True(t, flds[0].IsFlagValue())
+	require.True(t, flds[0].TypeRef.PflagValue)
+	require.NotNil(t, flds[0].StructRef)
+	require.True(t, flds[1].IsFlagValue())
+	require.Nil(t, flds[1].StructRef)
+	require.True(t, flds[2].IsFlagValue())
+	require.True(t, flds[2].TypeRef.TextValue)
+	require.True(t, flds[2].Pointer)
+	require.True(t, flds[3].IsFlagValue())
+	require.Equal(t, "std/net", flds[3].TypeRef.Package.Path)
+}
+
+func TestFinder_FindFieldsByStruct_Embedded(t *testing.T) {
+	if _, ok := os.LookupEnv("GOROOT"); !ok {
+		t.Setenv("GOROOT", runtime.GOROOT())
+	}
+
+	fldsvc, stsvc, fset := newFinder()
+
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	st, err := stsvc.FindStructByDirectoryAndName("../../../_test/testdata/qux", "CreateRequest")
+	require.NoError(t, err)
+
+	flds, err := fldsvc.FindFieldsByStruct(st)
+	require.NoError(t, err)
+	require.Len(t, flds, 3)
+
+	// io.Reader is looked up and reported once, however many times the struct is visited
+	again, err := fldsvc.FindFieldsByStruct(st)
+	require.NoError(t, err)
+	require.Len(t, again, 3)
+	require.Equal(t, 1, strings.Count(logs.String(), "StructType not found"))
+	require.Equal(t, 1, strings.Count(logs.String(), "embedded field type not supported"))
+
+	require.Equal(t, "CommonOptions", flds[0].Name)
+	require.True(t, flds[0].Embedded)
+	require.False(t, flds[0].Pointer)
+	require.Equal(t, "CommonOptions", flds[0].StructRef.Name)
+	require.Equal(t, "Server", flds[1].Name)
+	require.True(t, flds[1].Embedded)
+	require.True(t, flds[1].Pointer)
+	require.Equal(t, "prefix", flds[1].Tag.Get("embed"))
+	require.Equal(t, "Name", flds[2].Name)
+	require.False(t, flds[2].Embedded)
+
+	position := fset.Position(flds[2].Pos)
+	require.Equal(t, "types.go", filepath.Base(position.Filename))
+	require.Equal(t, st.AST.StructType.Fields.List[3].Pos(), flds[2].Pos)
+}
+
+// newFinder returns a field finder along with the struct finder and the file set it shares.
+func newFinder() (projscan.FieldFinder, projscan.StructFinder, *token.FileSet) {
+	scanner := syntree.NewScanner(token.NewFileSet())
+	projsvc := proj.NewFinder(scanner)
+	pkgsvc := pkg.NewFinder(scanner, projsvc)
+	stsvc := st.NewFinder(scanner, projsvc, pkgsvc)
+	typsvc := typ.NewFinder(scanner, pkgsvc)
+
+	return NewFinder(pkgsvc, projsvc, stsvc, typsvc), stsvc, scanner.FileSet()
+}
@@ -0,0 +1,159 @@
+package typ
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/types"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slog"
+
+	"github.com/totvs-cloud/pflagstruct/internal/dir"
+	"github.com/totvs-cloud/pflagstruct/internal/syntree"
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+// Finder provides a way to find named types in Go packages using the go/types type checker.
+type Finder struct {
+	scanner  *syntree.Scanner       // Scanner is a syntree scanner.
+	packages projscan.PackageFinder // PackageFinder is a package finder.
+	importer types.Importer         // Importer resolves the imports of the type checked packages.
+	checked  map[string]*types.Package
+}
+
+// NewFinder creates a new Finder instance with a given Scanner and PackageFinder.
+func NewFinder(scanner *syntree.Scanner, packages projscan.PackageFinder) *Finder {
+	return &Finder{
+		scanner:  scanner,
+		packages: packages,
+		importer: importer.ForCompiler(scanner.FileSet(), "source", nil),
+		checked:  make(map[string]*types.Package),
+	}
+}
+
+// FindTypeByDirectoryAndName searches for a named type in a directory by its name, resolving its underlying type and
+// whether a pointer to it is able to parse flag values by itself. It returns an error if the type does not exist.
+func (f *Finder) FindTypeByDirectoryAndName(directory, typeName string) (*projscan.TypeRef, error) {
+	directory, err := dir.AbsolutePath(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := f.packages.FindPackageByDirectory(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	checked, err := f.check(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := checked.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, errors.Errorf("no types named %q were found at the path %q", typeName, directory)
+	}
+
+	ref := &projscan.TypeRef{
+		Package:    pkg,
+		Name:       typeName,
+		PflagValue: types.Implements(types.NewPointer(obj.Type()), pflagValue),
+		TextValue:  types.Implements(types.NewPointer(obj.Type()), textValue),
+	}
+
+	switch underlying := obj.Type().Underlying().(type) {
+	case *types.Struct:
+		ref.Struct = true
+	case *types.Basic:
+		// types.Typ normalizes aliases such as byte and rune to their canonical names
+		if fieldType, err := projscan.ParseFieldType(types.Typ[underlying.Kind()].Name()); err == nil {
+			ref.Underlying = fieldType
+			ref.Values = findConsts(checked, obj.Type(), underlying)
+		}
+	}
+
+	return ref, nil
+}
+
+// findConsts returns the constants of the given string or integer type declared in the package, in declaration order.
+// Constants sharing the same value are reported once.
+func findConsts(checked *types.Package, typ types.Type, basic *types.Basic) []*projscan.Const {
+	if basic.Info()&(types.IsString|types.IsInteger) == 0 {
+		return nil
+	}
+
+	consts := make([]*types.Const, 0)
+	for _, name := range checked.Scope().Names() {
+		if c, ok := checked.Scope().Lookup(name).(*types.Const); ok && types.Identical(c.Type(), typ) {
+			consts = append(consts, c)
+		}
+	}
+
+	sort.Slice(consts, func(i, j int) bool {
+		return consts[i].Pos() < consts[j].Pos()
+	})
+
+	result := make([]*projscan.Const, 0, len(consts))
+	seen := make(map[string]bool)
+
+	for _, c := range consts {
+		value := c.Val().ExactString()
+		if c.Val().Kind() == constant.String {
+			value = constant.StringVal(c.Val())
+		}
+
+		if seen[value] {
+			continue
+		}
+
+		seen[value] = true
+		result = append(result, &projscan.Const{Name: c.Name(), Value: value})
+	}
+
+	return result
+}
+
+// check type checks the given package, caching the result by directory.
+func (f *Finder) check(pkg *projscan.Package) (*types.Package, error) {
+	if checked, ok := f.checked[pkg.Directory]; ok {
+		return checked, nil
+	}
+
+	files, err := f.scanner.ScanDirectory(pkg.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	filenames := make([]string, 0, len(files))
+	for filename, file := range files {
+		if file.Name.String() == pkg.Name {
+			filenames = append(filenames, filename)
+		}
+	}
+
+	sort.Strings(filenames)
+
+	syntax := make([]*ast.File, 0, len(filenames))
+	for _, filename := range filenames {
+		syntax = append(syntax, files[filename])
+	}
+
+	conf := &types.Config{
+		Importer: f.importer,
+		Error: func(err error) {
+			// a partially checked package is enough to resolve most declarations
+			slog.Debug("type checking error", slog.String("Package", pkg.Path), slog.String("Error", err.Error()))
+		},
+	}
+
+	checked, _ := conf.Check(pkg.Path, f.scanner.FileSet(), syntax, nil)
+	if checked == nil {
+		return nil, errors.Errorf("unable to type check the package %q", pkg.Path)
+	}
+
+	f.checked[pkg.Directory] = checked
+
+	return checked, nil
+}
@@ -0,0 +1,87 @@
+package typ_test
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/pkg"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/proj"
+	"github.com/totvs-cloud/pflagstruct/internal/scan/typ"
+	"github.com/totvs-cloud/pflagstruct/internal/syntree"
+	"github.com/totvs-cloud/pflagstruct/projscan"
+)
+
+func TestFinder_FindTypeByDirectoryAndName(t *testing.T) {
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "Region")
+		require.NoError(t, err)
+		require.Equal(t, "Region", ref.Name)
+		require.Equal(t, projscan.FieldTypeString, ref.Underlying)
+		require.Equal(t, "github.com/totvs-cloud/pflagstruct/_test/testdata/qux", ref.Package.Path)
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "Tier")
+		require.NoError(t, err)
+		require.True(t, ref.IsEnum())
+		require.Equal(t, []string{"basic", "premium"}, ref.ValueList())
+		require.Equal(t, "TierBasic", ref.Values[0].Name)
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "Region")
+		require.NoError(t, err)
+		require.False(t, ref.IsEnum())
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux/units", "Bytes")
+		require.NoError(t, err)
+		require.Equal(t, projscan.FieldTypeUint64, ref.Underlying)
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "Deployment")
+		require.NoError(t, err)
+		require.False(t, ref.Underlying.IsValid())
+		require.True(t, ref.Struct)
+		require.False(t, ref.IsFlagValue())
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "CIDR")
+		require.NoError(t, err)
+		require.True(t, ref.PflagValue)
+		require.False(t, ref.TextValue)
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "Labels")
+		require.NoError(t, err)
+		require.True(t, ref.PflagValue)
+		require.False(t, ref.Struct)
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		ref, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "Version")
+		require.NoError(t, err)
+		require.False(t, ref.PflagValue)
+		require.True(t, ref.TextValue)
+	})
+	t.Run("", func(t *testing.T) {
+		svc := newFinder()
+		_, err := svc.FindTypeByDirectoryAndName("../../../_test/testdata/qux", "Missing")
+		require.Error(t, err)
+	})
+}
+
+func newFinder() projscan.TypeFinder {
+	scanner := syntree.NewScanner(token.NewFileSet())
+	projFinder := proj.NewFinder(scanner)
+	pkgFinder := pkg.NewFinder(scanner, projFinder)
+	typFinder := typ.NewFinder(scanner, pkgFinder)
+
+	return typFinder
+}
@@ -0,0 +1,47 @@
+package typ
+
+import (
+	"go/token"
+	"go/types"
+)
+
+var (
+	// pflagValue mirrors the github.com/spf13/pflag.Value interface.
+	pflagValue = newInterface(
+		newMethod("String", nil, []types.Type{types.Typ[types.String]}),
+		newMethod("Set", []types.Type{types.Typ[types.String]}, []types.Type{errorType()}),
+		newMethod("Type", nil, []types.Type{types.Typ[types.String]}),
+	)
+
+	// textValue combines the encoding.TextUnmarshaler and encoding.TextMarshaler interfaces.
+	textValue = newInterface(
+		newMethod("UnmarshalText", []types.Type{types.NewSlice(types.Typ[types.Byte])}, []types.Type{errorType()}),
+		newMethod("MarshalText", nil, []types.Type{types.NewSlice(types.Typ[types.Byte]), errorType()}),
+	)
+)
+
+// newInterface creates a complete interface type with the given methods.
+func newInterface(methods ...*types.Func) *types.Interface {
+	return types.NewInterfaceType(methods, nil).Complete()
+}
+
+// newMethod creates an interface method with the given parameter and result types.
+func newMethod(name string, params, results []types.Type) *types.Func {
+	signature := types.NewSignatureType(nil, nil, nil, newTuple(params), newTuple(results), false)
+	return types.NewFunc(token.NoPos, nil, name, signature)
+}
+
+// newTuple creates a tuple of unnamed variables with the given types.
+func newTuple(typs []types.Type) *types.Tuple {
+	vars := make([]*types.Var, 0, len(typs))
+	for _, typ := range typs {
+		vars = append(vars, types.NewVar(token.NoPos, nil, "", typ))
+	}
+
+	return types.NewTuple(vars...)
+}
+
+// errorType returns the predeclared error type.
+func errorType() types.Type {
+	return types.Universe.Lookup("error").Type()
+}
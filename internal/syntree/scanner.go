@@ -21,6 +21,11 @@ func NewScanner(fset *token.FileSet) *Scanner {
 	return &Scanner{fset: fset}
 }
 
+// FileSet returns the fileset that holds the positions of every scanned file.
+func (s *Scanner) FileSet() *token.FileSet {
+	return s.fset
+}
+
 // ScanDirectory scans a directory for Go files and returns a map with the file names as keys and the corresponding
 // AST nodes as values.
 func (s *Scanner) ScanDirectory(directory string) (map[string]*ast.File, error) {
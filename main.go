@@ -19,12 +19,13 @@ import (
 	scanpkg "github.com/totvs-cloud/pflagstruct/internal/scan/pkg"
 	scanproj "github.com/totvs-cloud/pflagstruct/internal/scan/proj"
 	scanst "github.com/totvs-cloud/pflagstruct/internal/scan/st"
+	scantyp "github.com/totvs-cloud/pflagstruct/internal/scan/typ"
 	"github.com/totvs-cloud/pflagstruct/internal/syntree"
 )
 
 var (
-	directory, pkgPath, structName, destination string
-	debug                                       bool
+	directory, pkgPath, structName, destination, envPrefix, backend string
+	debug, shortPrefixes, viper, completions                        bool
 )
 
 func NewCommand() (*cobra.Command, error) {
@@ -34,6 +35,11 @@ func NewCommand() (*cobra.Command, error) {
 		structNameFlagName  = "struct-name"
 		destinationFlagName = "destination"
 		debugFlagName       = "debug"
+		shortPrefixFlagName = "short-prefixes"
+		envPrefixFlagName   = "env-prefix"
+		viperFlagName       = "viper"
+		completionsFlagName = "completions"
+		backendFlagName     = "backend"
 	)
 
 	cmd := &cobra.Command{
@@ -63,7 +69,8 @@ func NewCommand() (*cobra.Command, error) {
 			projects := scanproj.NewFinder(scanner)
 			packages := scanpkg.NewFinder(scanner, projects)
 			structs := scanst.NewFinder(scanner, projects, packages)
-			fields := scanfld.NewFinder(packages, projects, structs)
+			types := scantyp.NewFinder(scanner, packages)
+			fields := scanfld.NewFinder(packages, projects, structs, types)
 
 			if directory == "" {
 				proj, err := projects.FindProjectByDirectory(destination)
@@ -79,7 +86,7 @@ func NewCommand() (*cobra.Command, error) {
 				directory = pkg.Directory
 			}
 
-			filepath, err := code.NewGenerator(fields, packages, projects, structs).Generate(directory, structName, destination)
+			filepath, err := code.NewGenerator(fields, packages, projects, structs, scanner.FileSet(), code.Options{ShortPrefixes: shortPrefixes, EnvPrefix: envPrefix, Viper: viper, Completions: completions, Backend: backend}).Generate(directory, structName, destination)
 			if err != nil {
 				return err
 			}
@@ -92,6 +99,11 @@ func NewCommand() (*cobra.Command, error) {
 	cmd.Flags().StringVar(&pkgPath, packageFlagName, "", "specifies the package path of the struct definition. This flag is required if the --directory flag is not informed")
 	cmd.Flags().StringVar(&directory, directoryFlagName, "", "specifies the path where the source file containing the struct definition is located. This flag is required if the --package flag is not informed")
 	cmd.Flags().StringVar(&destination, destinationFlagName, ".", "specifies the path where the generated code will be saved")
+	cmd.Flags().BoolVar(&shortPrefixes, shortPrefixFlagName, false, "prefixes the flags of nested structs with the name of the innermost struct field only, as in versions prior to full path prefixes")
+	cmd.Flags().StringVar(&envPrefix, envPrefixFlagName, "", "makes the generated getters read flags that are not set from environment variables named after them and prefixed with the given prefix, such as APP_QUUX_NAME for --quux-name")
+	cmd.Flags().BoolVar(&viper, viperFlagName, false, "generates the functions that bind the flags to viper keys named after the nested fields, such as quux.quuz.id, and build the struct from viper")
+	cmd.Flags().BoolVar(&completions, completionsFlagName, false, "generates the function registering the values of enumerations as shell completions of a cobra command")
+	cmd.Flags().StringVar(&backend, backendFlagName, code.PflagBackendName, "specifies the flags package the generated code is written for: pflag, for cobra commands, flag, for the standard library, or cli, for github.com/urfave/cli/v2")
 	cmd.Flags().BoolVar(&debug, debugFlagName, false, "enables debug mode, which provides additional output for debugging purposes")
 
 	return cmd, nil
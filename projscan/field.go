@@ -3,24 +3,41 @@
 package projscan
 
 import (
+	"go/token"
+	"reflect"
 	"strings"
 )
 
 // Field represents a field of a struct.
 type Field struct {
-	Name         string    // Name of the field
-	Type         FieldType // Type of the field
-	Doc          string    // Documentation for the field
-	StructRef    *Struct   // Reference to the struct that contains this field
-	Pointer      bool      // Indicates whether the field is a pointer type or not
-	Array        bool      // Indicates whether the field is an array type or not
-	ArrayPointer bool      // Indicates whether the field is a pointer to an array type or not
+	Name         string            // Name of the field
+	Type         FieldType         // Type of the field
+	Doc          string            // Documentation for the field
+	StructRef    *Struct           // Reference to the struct that contains this field
+	Pointer      bool              // Indicates whether the field is a pointer type or not
+	Array        bool              // Indicates whether the field is an array type or not
+	ArrayPointer bool              // Indicates whether the field is a pointer to an array type or not
+	Tag          reflect.StructTag // Struct tag of the field
+	TypeRef      *TypeRef          // Reference to the named type of the field, when it is not a struct or parses flag values by itself
+	Embedded     bool              // Indicates whether the field is embedded, in which case it is named after its type
+	Pos          token.Pos         // Position of the field declaration in the file set of the scanner
+	MapKey       *Field            // Type of the keys of the map, when the field is a map
+	MapValue     *Field            // Type of the values of the map, when the field is a map
 }
 
 // FieldType defines the available field types in Go
-// ENUM(string, bool, int, int8, int16, int32, int64, float32, float64)
+// ENUM(string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64)
 type FieldType string
 
+// DurationFieldType is the type of the fields declared as the standard library time.Duration.
+const DurationFieldType FieldType = "time.Duration"
+
+// knownKeyValues are the structs whose slices are read from key-value pairs without a `keyvalue` struct tag, keyed by
+// their package path and name, along with the names of the fields holding the key and the value.
+var knownKeyValues = map[string][2]string{
+	"github.com/totvs-cloud/tcloud-iaas-sdk/pkg/tags.Tags": {"Name", "Value"},
+}
+
 // FromStandardLibrary returns true if the field's containing struct is part of the Go standard library.
 func (s *Field) FromStandardLibrary() bool {
 	if s.StructRef == nil {
@@ -30,8 +47,40 @@ func (s *Field) FromStandardLibrary() bool {
 	return strings.HasPrefix(s.StructRef.Package.Path, "std/")
 }
 
+// IsDuration returns true if the field type is the standard library time.Duration.
+func (s *Field) IsDuration() bool {
+	return s.StructRef == nil && s.Type == DurationFieldType
+}
+
+// IsTime returns true if the field type is the standard library time.Time.
+func (s *Field) IsTime() bool {
+	return s.HasStructRef("std/time", "Time")
+}
+
+// IsFlagValue returns true if the field type implements pflag.Value or encoding.TextUnmarshaler.
+func (s *Field) IsFlagValue() bool {
+	return s.TypeRef != nil && s.TypeRef.IsFlagValue()
+}
+
+// KnownKeyValue returns the names of the fields holding the key and the value of the struct of the field, if it is
+// known to be read from key-value pairs, such as the tags of the TCloud SDK.
+func (s *Field) KnownKeyValue() (key, value string, ok bool) {
+	if s.StructRef == nil || s.StructRef.Package == nil {
+		return "", "", false
+	}
+
+	names, ok := knownKeyValues[s.StructRef.Package.Path+"."+s.StructRef.Name]
+
+	return names[0], names[1], ok
+}
+
+// IsTCloudTags returns true if the field holds the tags of the TCloud SDK.
+//
+// Deprecated: the tags of the TCloud SDK are one of the structs known to be read from key-value pairs, so use
+// KnownKeyValue instead, along with the `keyvalue` struct tag for the structs of other packages.
 func (s *Field) IsTCloudTags() bool {
-	return s.HasStructRef("github.com/totvs-cloud/tcloud-iaas-sdk/pkg/tags", "Tags")
+	_, _, ok := s.KnownKeyValue()
+	return ok
 }
 
 func (s *Field) HasStructRef(path, name string) bool {
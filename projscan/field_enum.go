@@ -26,6 +26,16 @@ const (
 	FieldTypeInt32 FieldType = "int32"
 	// FieldTypeInt64 is a FieldType of type int64.
 	FieldTypeInt64 FieldType = "int64"
+	// FieldTypeUint is a FieldType of type uint.
+	FieldTypeUint FieldType = "uint"
+	// FieldTypeUint8 is a FieldType of type uint8.
+	FieldTypeUint8 FieldType = "uint8"
+	// FieldTypeUint16 is a FieldType of type uint16.
+	FieldTypeUint16 FieldType = "uint16"
+	// FieldTypeUint32 is a FieldType of type uint32.
+	FieldTypeUint32 FieldType = "uint32"
+	// FieldTypeUint64 is a FieldType of type uint64.
+	FieldTypeUint64 FieldType = "uint64"
 	// FieldTypeFloat32 is a FieldType of type float32.
 	FieldTypeFloat32 FieldType = "float32"
 	// FieldTypeFloat64 is a FieldType of type float64.
@@ -54,6 +64,11 @@ var _FieldTypeValue = map[string]FieldType{
 	"int16":   FieldTypeInt16,
 	"int32":   FieldTypeInt32,
 	"int64":   FieldTypeInt64,
+	"uint":    FieldTypeUint,
+	"uint8":   FieldTypeUint8,
+	"uint16":  FieldTypeUint16,
+	"uint32":  FieldTypeUint32,
+	"uint64":  FieldTypeUint64,
 	"float32": FieldTypeFloat32,
 	"float64": FieldTypeFloat64,
 }
@@ -1,5 +1,7 @@
 package projscan
 
+import "strings"
+
 // Package represents a Go package.
 type Package struct {
 	Directory string // Path to the directory containing the package's source code
@@ -7,6 +9,17 @@ type Package struct {
 	Name      string // Name of the package
 }
 
+// ImportPath returns the path used to import the package, which for standard library packages is the path without
+// the "std/" prefix.
+func (p *Package) ImportPath() string {
+	return strings.TrimPrefix(p.Path, "std/")
+}
+
+// FromStandardLibrary returns true if the package is part of the Go standard library.
+func (p *Package) FromStandardLibrary() bool {
+	return strings.HasPrefix(p.Path, "std/")
+}
+
 // PackageFinder provides a way to find a Go package by its directory or by its path and project.
 type PackageFinder interface {
 	FindPackageByDirectory(directory string) (*Package, error)
@@ -0,0 +1,43 @@
+package projscan
+
+// TypeRef represents a named Go type, such as `type Region string`.
+type TypeRef struct {
+	Package    *Package  // Package that declares the type
+	Name       string    // Name of the type
+	Underlying FieldType // Underlying type of the named type, empty if it is not one of the available field types
+	Values     []*Const  // Constants declared with the named type, when it is used as an enumeration
+	Struct     bool      // Indicates whether the underlying type is a struct
+	PflagValue bool      // Indicates whether a pointer to the type implements pflag.Value
+	TextValue  bool      // Indicates whether a pointer to the type implements encoding.TextUnmarshaler and encoding.TextMarshaler
+}
+
+// Const represents a constant declared with a named type.
+type Const struct {
+	Name  string // Name of the constant
+	Value string // Value of the constant, unquoted for strings
+}
+
+// IsEnum returns true if the named type has a set of constants declared with it.
+func (t *TypeRef) IsEnum() bool {
+	return len(t.Values) > 0
+}
+
+// IsFlagValue returns true if a pointer to the named type is able to parse flag values by itself.
+func (t *TypeRef) IsFlagValue() bool {
+	return t.PflagValue || t.TextValue
+}
+
+// ValueList returns the values of the constants declared with the named type.
+func (t *TypeRef) ValueList() []string {
+	values := make([]string, 0, len(t.Values))
+	for _, v := range t.Values {
+		values = append(values, v.Value)
+	}
+
+	return values
+}
+
+// TypeFinder provides a way to find a named type by its directory and name.
+type TypeFinder interface {
+	FindTypeByDirectoryAndName(directory, typeName string) (*TypeRef, error)
+}